@@ -16,6 +16,20 @@ const (
     wordSeparator       = "-"
 )
 
+const (
+    PositionAny   = "any"   // issue keys may appear anywhere in the branch name
+    PositionStart = "start" // issue keys must lead the last segment of the branch name
+)
+
+var DefaultKeySeparators = []string{"_", "-", "."}
+
+// KeyPattern configures how issue keys are extracted from branch names.
+type KeyPattern struct {
+    Projects   []string // allowed project keys, any project if empty
+    Separators []string // characters around a key, '/' is always one
+    Position   string
+}
+
 type Branch struct {
     Type           Type
     ExcludePhrases []string
@@ -25,6 +39,7 @@ type Branch struct {
     secondPassKebabRegx *regexp.Regexp
     issueRegx           *regexp.Regexp
     excludePhrasesRegx  []*regexp.Regexp
+    keyPattern          KeyPattern
 }
 
 func New(branchType Type, excludePhrases []string) *Branch {
@@ -36,12 +51,46 @@ func New(branchType Type, excludePhrases []string) *Branch {
     b.stripRegx = regexp.MustCompile("[^a-zA-Z0-9]+")
     b.firstPassKebabRegx = regexp.MustCompile("([A-Z]+)([A-Z][a-z])")
     b.secondPassKebabRegx = regexp.MustCompile("([a-z])([A-Z])")
-    b.issueRegx = regexp.MustCompile(`[A-Z]+-\d+_`) // looking for XXXX-0000_
     b.excludePhrasesRegx = prepareExcludeRegx(excludePhrases)
+    _ = b.SetKeyPattern(KeyPattern{Separators: DefaultKeySeparators, Position: PositionAny})
 
     return b
 }
 
+// SetKeyPattern replaces the rules used by ExtractIssueKeysFromBranch.
+func (b *Branch) SetKeyPattern(p KeyPattern) error {
+    switch p.Position {
+    case "":
+        p.Position = PositionAny
+    case PositionAny, PositionStart:
+    default:
+        return fmt.Errorf("unsupported issue key position %q", p.Position)
+    }
+
+    // empty entries, e.g. of TWIG_ISSUE_PROJECTS="ABC,", would match a key without a project
+    quoted := make([]string, 0, len(p.Projects))
+    for _, project := range p.Projects {
+        if project = strings.TrimSpace(project); project != "" {
+            quoted = append(quoted, regexp.QuoteMeta(strings.ToUpper(project)))
+        }
+    }
+
+    projects := `[A-Z][A-Z0-9]*`
+    if len(quoted) > 0 {
+        projects = fmt.Sprintf("(?:%s)", strings.Join(quoted, "|"))
+    }
+
+    b.issueRegx = regexp.MustCompile(projects + `-\d+`) // looking for XXXX-0000
+    b.keyPattern = p
+
+    return nil
+}
+
+// KeyRegexp returns the expression issue keys are matched with, e.g. to find them in commit messages.
+func (b *Branch) KeyRegexp() string {
+    return b.issueRegx.String()
+}
+
 func prepareExcludeRegx(excludes []string) []*regexp.Regexp {
     regexps := make([]*regexp.Regexp, len(excludes))
 
@@ -112,19 +161,77 @@ func (b *Branch) camelToKebab(in string) string {
     return strings.ToLower(kebab)
 }
 
+// ExtractIssueNameFromBranch returns the first issue key of the branch.
 func (b *Branch) ExtractIssueNameFromBranch(branchName string) (string, error) {
+    keys, err := b.ExtractIssueKeysFromBranch(branchName)
+    if err != nil {
+        return "", err
+    }
+
+    return keys[0], nil
+}
+
+// ExtractIssueKeysFromBranch returns every issue key of the branch in order of appearance.
+func (b *Branch) ExtractIssueKeysFromBranch(branchName string) ([]string, error) {
     log.Debug().Println(fmt.Sprintf("Before extract %q", branchName))
 
-    match := b.issueRegx.FindString(branchName)
-    match = strings.TrimSpace(match)
-    match = strings.TrimSuffix(match, issueTypeSeparator)
+    name := strings.TrimSpace(branchName)
+    offset := 0
+
+    if b.keyPattern.Position == PositionStart {
+        offset = strings.LastIndex(name, branchTypeSeparator) + 1
+    }
+
+    keys := make([]string, 0)
+    expected := offset
+
+    for _, loc := range b.issueRegx.FindAllStringIndex(name, -1) {
+        start, end := loc[0], loc[1]
+
+        if !b.isKeyBoundary(name, start-1) || !b.isKeyBoundary(name, end) {
+            continue
+        }
+
+        if b.keyPattern.Position == PositionStart {
+            if start < offset {
+                continue
+            }
 
-    if match == "" {
-        return "", errors.New("no issue match")
+            if start != expected {
+                break
+            }
+
+            expected = end + 1
+        }
+
+        keys = append(keys, name[start:end])
     }
 
-    log.Debug().Println(fmt.Sprintf("After extract %q", match))
-    return match, nil
+    if len(keys) == 0 {
+        return nil, errors.New("no issue match")
+    }
+
+    log.Debug().Println(fmt.Sprintf("After extract %q", keys))
+    return keys, nil
+}
+
+func (b *Branch) isKeyBoundary(name string, i int) bool {
+    if i < 0 || i >= len(name) {
+        return true
+    }
+
+    char := name[i : i+1]
+    if char == branchTypeSeparator {
+        return true
+    }
+
+    for _, separator := range b.keyPattern.Separators {
+        if char == separator {
+            return true
+        }
+    }
+
+    return false
 }
 
 func InputToBranchType(input string) (Type, error) {
@@ -156,6 +263,16 @@ func InputToBranchType(input string) (Type, error) {
     }
 }
 
+// TypeFromBranch returns the type a branch name starts with, e.g. FIX for "fix/ABC-1_login".
+func TypeFromBranch(branchName string) (Type, error) {
+    prefix, _, ok := strings.Cut(branchName, branchTypeSeparator)
+    if !ok {
+        return NULL, fmt.Errorf("branch %q has no type", branchName)
+    }
+
+    return InputToBranchType(prefix)
+}
+
 func ConvertIssueTypesToMap(issueTypes []network.IssueType) (map[string]Type, error) {
     issueMap := make(map[string]Type)
 
@@ -1,6 +1,7 @@
 package branch
 
 import (
+    "slices"
     "testing"
     "twig/log"
     "twig/network"
@@ -199,3 +200,139 @@ func TestBuildNameNumbersInBetween(t *testing.T) {
         t.Errorf(`BuildName(type, issue, phrases) = %q, want match for %q`, subject, want)
     }
 }
+
+func TestExtractIssueNameFromBranchOptimisticCase(t *testing.T) {
+    in := "fix/TST-101_my-super-branch-summary"
+
+    want := "TST-101"
+    subject, _ := New(NULL, nil).ExtractIssueNameFromBranch(in)
+
+    if subject != want {
+        t.Errorf(`ExtractIssueNameFromBranch(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestExtractIssueKeysFromBranchDashSeparator(t *testing.T) {
+    in := "feature/ABC-12-fix"
+
+    want := []string{"ABC-12"}
+    subject, _ := New(NULL, nil).ExtractIssueKeysFromBranch(in)
+
+    if !slices.Equal(subject, want) {
+        t.Errorf(`ExtractIssueKeysFromBranch(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestExtractIssueKeysFromBranchMultipleKeys(t *testing.T) {
+    in := "ABC-1_ABC-2_combo"
+
+    want := []string{"ABC-1", "ABC-2"}
+    subject, _ := New(NULL, nil).ExtractIssueKeysFromBranch(in)
+
+    if !slices.Equal(subject, want) {
+        t.Errorf(`ExtractIssueKeysFromBranch(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestExtractIssueKeysFromBranchKeyAtTheEnd(t *testing.T) {
+    in := "chore/update-deps-ABC-7"
+
+    want := []string{"ABC-7"}
+    subject, _ := New(NULL, nil).ExtractIssueKeysFromBranch(in)
+
+    if !slices.Equal(subject, want) {
+        t.Errorf(`ExtractIssueKeysFromBranch(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestExtractIssueKeysFromBranchNotBounded(t *testing.T) {
+    in := "fix/xABC-12fix"
+
+    _, err := New(NULL, nil).ExtractIssueKeysFromBranch(in)
+
+    if err == nil {
+        t.Errorf(`ExtractIssueKeysFromBranch(in) = nil, want error`)
+    }
+}
+
+func TestExtractIssueKeysFromBranchProjectAllowlist(t *testing.T) {
+    in := "fix/ABC-1_DEF-2_combo"
+
+    b := New(NULL, nil)
+    _ = b.SetKeyPattern(KeyPattern{Projects: []string{"def"}, Separators: DefaultKeySeparators})
+
+    want := []string{"DEF-2"}
+    subject, _ := b.ExtractIssueKeysFromBranch(in)
+
+    if !slices.Equal(subject, want) {
+        t.Errorf(`ExtractIssueKeysFromBranch(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestExtractIssueKeysFromBranchEmptyProject(t *testing.T) {
+    in := "fix/ABC-1_-2_combo"
+
+    b := New(NULL, nil)
+    _ = b.SetKeyPattern(KeyPattern{Projects: []string{"ABC", " "}, Separators: DefaultKeySeparators})
+
+    want := []string{"ABC-1"}
+    subject, _ := b.ExtractIssueKeysFromBranch(in)
+
+    if !slices.Equal(subject, want) {
+        t.Errorf(`ExtractIssueKeysFromBranch(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestExtractIssueKeysFromBranchPositionStart(t *testing.T) {
+    in := "fix/ABC-1_ABC-2_combo-ABC-3"
+
+    b := New(NULL, nil)
+    _ = b.SetKeyPattern(KeyPattern{Separators: DefaultKeySeparators, Position: PositionStart})
+
+    want := []string{"ABC-1", "ABC-2"}
+    subject, _ := b.ExtractIssueKeysFromBranch(in)
+
+    if !slices.Equal(subject, want) {
+        t.Errorf(`ExtractIssueKeysFromBranch(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestExtractIssueKeysFromBranchPositionStartNoLeadingKey(t *testing.T) {
+    in := "fix/combo_ABC-1"
+
+    b := New(NULL, nil)
+    _ = b.SetKeyPattern(KeyPattern{Separators: DefaultKeySeparators, Position: PositionStart})
+
+    _, err := b.ExtractIssueKeysFromBranch(in)
+
+    if err == nil {
+        t.Errorf(`ExtractIssueKeysFromBranch(in) = nil, want error`)
+    }
+}
+
+func TestSetKeyPatternUnsupportedPosition(t *testing.T) {
+    err := New(NULL, nil).SetKeyPattern(KeyPattern{Position: "middle"})
+
+    if err == nil {
+        t.Errorf(`SetKeyPattern(pattern) = nil, want error`)
+    }
+}
+
+func TestTypeFromBranch(t *testing.T) {
+    in := "fix/ABC-1_handle-null-token"
+
+    want := FIX
+    subject, err := TypeFromBranch(in)
+
+    if subject != want || err != nil {
+        t.Errorf(`TypeFromBranch(in) = %v %v, want match for %v`, subject, err, want)
+    }
+}
+
+func TestTypeFromBranchWithoutType(t *testing.T) {
+    for _, in := range []string{"ABC-1_handle-null-token", "release/1.0"} {
+        if subject, err := TypeFromBranch(in); err == nil {
+            t.Errorf(`TypeFromBranch(%q) = %v, want error`, in, subject)
+        }
+    }
+}
@@ -0,0 +1,26 @@
+package branch
+
+import (
+    "fmt"
+    "strings"
+    "twig/log"
+    "twig/util"
+)
+
+// IsProtected reports whether the branch name matches any of the glob patterns.
+// A '*' matches any sequence of characters including '/', a '?' matches exactly one character.
+func IsProtected(branchName string, patterns []string) bool {
+    for _, pattern := range patterns {
+        pattern = strings.TrimSpace(pattern)
+        if pattern == "" {
+            continue
+        }
+
+        if util.MatchGlob(pattern, branchName) {
+            log.Debug().Println(fmt.Sprintf("Branch %q is protected by %q", branchName, pattern))
+            return true
+        }
+    }
+
+    return false
+}
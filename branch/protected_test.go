@@ -0,0 +1,85 @@
+package branch
+
+import "testing"
+
+var protectedPatterns = []string{"development", "main", "master", "release/*", "hotfix/*"}
+
+func TestIsProtectedExactName(t *testing.T) {
+    in := "main"
+
+    subject := IsProtected(in, protectedPatterns)
+
+    if !subject {
+        t.Errorf(`IsProtected(%q, patterns) = %t, want match for %t`, in, subject, true)
+    }
+}
+
+func TestIsProtectedReleaseWithIssueKey(t *testing.T) {
+    in := "release/REL-2024_q1"
+
+    subject := IsProtected(in, protectedPatterns)
+
+    if !subject {
+        t.Errorf(`IsProtected(%q, patterns) = %t, want match for %t`, in, subject, true)
+    }
+}
+
+func TestIsProtectedNestedRelease(t *testing.T) {
+    in := "release/2024/REL-1_q1"
+
+    subject := IsProtected(in, protectedPatterns)
+
+    if !subject {
+        t.Errorf(`IsProtected(%q, patterns) = %t, want match for %t`, in, subject, true)
+    }
+}
+
+func TestIsProtectedFeatureBranch(t *testing.T) {
+    in := "feat/TST-101_release-notes"
+
+    subject := IsProtected(in, protectedPatterns)
+
+    if subject {
+        t.Errorf(`IsProtected(%q, patterns) = %t, want match for %t`, in, subject, false)
+    }
+}
+
+func TestIsProtectedPrefixIsNotEnough(t *testing.T) {
+    in := "main-TST-101_fix"
+
+    subject := IsProtected(in, protectedPatterns)
+
+    if subject {
+        t.Errorf(`IsProtected(%q, patterns) = %t, want match for %t`, in, subject, false)
+    }
+}
+
+func TestIsProtectedSingleCharacter(t *testing.T) {
+    in := "v1/TST-101_fix"
+
+    subject := IsProtected(in, []string{"v?/*"})
+
+    if !subject {
+        t.Errorf(`IsProtected(%q, patterns) = %t, want match for %t`, in, subject, true)
+    }
+}
+
+func TestIsProtectedEscapesRegexSymbols(t *testing.T) {
+    in := "release-1x0"
+
+    subject := IsProtected(in, []string{"release-1.0"})
+
+    if subject {
+        t.Errorf(`IsProtected(%q, patterns) = %t, want match for %t`, in, subject, false)
+    }
+}
+
+func TestIsProtectedEmptyPatterns(t *testing.T) {
+    in := "main"
+
+    subject := IsProtected(in, []string{"", " "})
+
+    if subject {
+        t.Errorf(`IsProtected(%q, patterns) = %t, want match for %t`, in, subject, false)
+    }
+}
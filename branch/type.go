@@ -41,6 +41,11 @@ const (
     TestShort     = "t"
 )
 
+// Types returns the names of all branch types, e.g. "fix".
+func Types() []string {
+    return []string{Build, Chore, Ci, Docs, Feat, Fix, Perf, Refactor, Revert, Style, Test}
+}
+
 func (t Type) ToString() string {
     switch t {
     case BUILD:
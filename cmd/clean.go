@@ -5,20 +5,19 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"maps"
-	"net/http"
 	"slices"
 	"strings"
-	"sync"
-	"time"
 	"twig/branch"
 	"twig/common"
 	"twig/config"
+	"twig/git"
 	"twig/log"
 	"twig/network"
 )
 
 const (
 	requestLimit      = 5
+	workersLimit      = 5
 	itemsPerRequest   = 100
 	itemsThreshold    = 5
 	doneStatusId      = 3
@@ -28,101 +27,245 @@ const (
 )
 
 var (
-	rate           = time.Tick(time.Second / time.Duration(requestLimit))
-	mu             sync.Mutex
 	assignee       string
 	ignoreAssignee bool
+	onlyMerged     bool
+	cleanOutput    string
+	cleanRepos     []string
+	useWorkspace   bool
 	cleanCmd       = &cobra.Command{
 		Use:   cleanCmdName,
 		Short: "Deletes branches which have Jira tickets in 'Done' state",
 		Args:  cobra.NoArgs,
 	}
 	cleanLocalCmd = &cobra.Command{
-		Use:   cleanLocalCmdName,
-		Short: "Deletes only local branches which have Jira tickets in 'Done' state",
-		Args:  cobra.NoArgs,
-		Run:   runClean,
+		Use:         cleanLocalCmdName,
+		Short:       "Deletes only local branches which have Jira tickets in 'Done' state",
+		Args:        cobra.NoArgs,
+		Run:         runClean,
+		Annotations: map[string]string{annotationRequiresGit: "true"},
 	}
 	cleanAllCmd = &cobra.Command{
-		Use:   cleanAllCmdName,
-		Short: "Deletes remote and local branches which have Jira tickets in 'Done' state",
-		Args:  cobra.NoArgs,
-		Run:   runClean,
+		Use:         cleanAllCmdName,
+		Short:       "Deletes remote and local branches which have Jira tickets in 'Done' state",
+		Args:        cobra.NoArgs,
+		Run:         runClean,
+		Annotations: map[string]string{annotationRequiresGit: "true"},
 	}
 )
 
 func runClean(cmd *cobra.Command, args []string) {
-	log.Debug().Println("clean: executing command")
+	if err := validateCleanOutput(); err != nil {
+		logCmdFatal(err)
+	}
 
-	email := config.GetString(config.ProjectEmail)
-	emailTokenName := config.FromToken(config.ProjectEmail)
+	if cleanOutput == outputJson {
+		// keep stdout clean for scripts, errors still go to stderr
+		log.SetLevel(log.ErrorLevel)
+		log.CreateRecorders()
+	}
 
-	username, err := common.ExtractUsernameFromEmail(email)
+	log.Debug().Println("clean: executing command")
+
+	paths, err := discoverCleanRepositories()
 	if err != nil {
-		we := fmt.Errorf(
-			"config: %q %w",
-			emailTokenName,
-			err,
-		)
-		logCmdFatal(we)
+		logCmdFatal(err)
+	}
+
+	isWorkspace := len(paths) > 0
+	if !isWorkspace {
+		paths = []string{""} // current repository
+	}
+
+	repos := make([]*cleanRepository, len(paths))
+
+	for i, path := range paths {
+		repo := &cleanRepository{Path: path}
+		repos[i] = repo
+
+		if isWorkspace {
+			log.Info().Println(fmt.Sprintf("\nRepository %q", path))
+		}
+
+		err := inRepository(path, func() error {
+			return prepareCleanRepository(repo)
+		})
+		if err != nil {
+			if !isWorkspace {
+				logCmdFatal(err)
+			}
+
+			log.Error().Println(fmt.Sprintf("%s: %s", path, err.Error()))
+			repo.Error = err.Error()
+			continue
+		}
+
+		if !isWorkspace && len(repo.issues) == 0 && len(repo.Branches) == 0 {
+			logCmdFatal(errors.New("no branches related to Jira issues were found"))
+		}
 	}
 
-	if assignee == "" {
-		assignee = username
+	queried := make(map[string]*profileIssues)
+
+	for _, repo := range repos {
+		if repo.Error != "" {
+			continue
+		}
+
+		err := inRepository(repo.Path, func() error {
+			// every issue is fetched once per profile, even if several repositories have branches for it
+			fetched, ok := queried[repo.profile]
+			if !ok {
+				fetched = queryProfileIssues(newJiraApi(), repos, repo.profile)
+				queried[repo.profile] = fetched
+			}
+
+			finishCleanRepository(cmd.Name(), repo, fetched.jiraIssues, fetched.fetchErrs)
+			return nil
+		})
+		if err != nil {
+			repo.Error = err.Error()
+		}
 	}
 
-	httpClient := &http.Client{}
-	client := network.NewHttpClient(httpClient)
-	api := network.NewJiraApi(client)
+	if isWorkspace {
+		err = printWorkspaceReport(repos)
+	} else {
+		err = printCleanReport(repos[0].Branches)
+	}
 
-	fetchCommand, err := common.ExecuteFetchPrune()
 	if err != nil {
 		logCmdFatal(err)
 	}
 
+	failed := 0
+	for _, repo := range repos {
+		failed += countOutcome(repo.Branches, outcomeFetchError)
+	}
+
+	if failed > 0 {
+		logCmdFatal(fmt.Errorf("failed to fetch Jira issues for %d branch(es)", failed))
+	}
+
+	if failedRepos := countFailedRepositories(repos); failedRepos > 0 {
+		logCmdFatal(fmt.Errorf("failed to clean %d repository(ies)", failedRepos))
+	}
+}
+
+// prepareCleanRepository updates the repository and pairs its branches with issue keys.
+// The profile and the assignee are recorded as well, both may differ between repositories.
+func prepareCleanRepository(repo *cleanRepository) error {
+	user, err := cleanAssignee()
+	if err != nil {
+		return err
+	}
+
+	gitRepo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	remote := config.GetString(config.BranchOrigin)
+	if remote == "" {
+		return fmt.Errorf("%q is not set", config.FromToken(config.BranchOrigin))
+	}
+
+	fetchCommand, err := common.ExecuteFetchPrune(gitRepo, remote)
+	if err != nil {
+		return err
+	}
+
 	if fetchCommand != "" {
 		log.Info().Println(fetchCommand)
 	}
 
-	if err := common.BranchStatus(); err != nil {
-		logCmdFatal(err)
+	if err := common.BranchStatus(gitRepo); err != nil {
+		return err
 	}
 
 	devBranch := config.GetString(config.BranchDefault)
-	hasBranch := common.HasBranch(devBranch)
+	location, err := common.FindBranch(gitRepo, remote, devBranch)
+	if err != nil {
+		return err
+	}
 
-	checkoutCommand, err := common.Checkout(devBranch, hasBranch)
+	checkoutCommand, err := common.Checkout(gitRepo, remote, devBranch, location)
 	if err != nil {
-		logCmdFatal(err)
+		return err
 	}
 
 	if checkoutCommand != "" {
 		log.Info().Println(checkoutCommand)
 	}
 
-	localBranches, err := common.GetLocalBranches()
+	localBranches, err := common.GetLocalBranches(gitRepo)
 	if err != nil {
-		logCmdFatal(err)
+		return err
 	}
 
-	issues, err := pairBranchesWithIssues(localBranches)
+	b, err := newIssueKeyBranch()
 	if err != nil {
-		logCmdFatal(err)
+		return err
 	}
 
-	remote := config.GetString(config.BranchOrigin)
-	if remote == "" {
-		logCmdFatal(fmt.Errorf("%q is not set", config.BranchOrigin))
-	}
+	protected := slices.Concat(config.GetStringArray(config.CleanProtected), []string{devBranch})
 
-	statuses, err := pairBranchesWithStatuses(api, issues)
+	repo.profile = config.ActiveProfile()
+	repo.user = user
+	repo.git = gitRepo
+	repo.remote = remote
+	repo.devBranch = devBranch
+	issues, protectedReports := excludeProtectedBranches(pairBranchesWithIssues(b, localBranches), protected)
+	issues, worktreeReports := excludeCheckedOutBranches(issues, localBranches)
+
+	repo.issues = issues
+	repo.Branches = append(protectedReports, worktreeReports...)
+
+	return nil
+}
+
+// finishCleanRepository decides on and deletes the branches of an already prepared repository.
+func finishCleanRepository(cmdName string, repo *cleanRepository, jiraIssues map[string]network.JiraIssue, fetchErrs map[string]error) {
+	reports := append(repo.Branches, evaluateBranches(repo.issues, repo.user, jiraIssues, fetchErrs)...)
+	slices.SortFunc(reports, func(a, b *branchReport) int {
+		return strings.Compare(a.Branch, b.Branch)
+	})
+
+	deleteBranches(repo.git, cmdName, repo.remote, repo.devBranch, reports)
+	repo.Branches = reports
+}
+
+// cleanAssignee returns --assignee or, by default, the username of project.email.
+func cleanAssignee() (string, error) {
+	username, err := common.ExtractUsernameFromEmail(config.GetString(config.ProjectEmail))
 	if err != nil {
-		log.Debug().Println(fmt.Sprintf("No issues, %s", err.Error()))
+		return "", fmt.Errorf("config: %q %w", config.FromToken(config.ProjectEmail), err)
 	}
 
-	if err = deleteBranchesIfAny(cmd.Name(), remote, statuses); err != nil {
-		log.Warn().Println(fmt.Sprintf("Hmm.. %s", err.Error()))
+	if assignee != "" {
+		return assignee, nil
 	}
+
+	return username, nil
+}
+
+// profileIssues holds the issues fetched for the repositories of a single profile.
+type profileIssues struct {
+	jiraIssues map[string]network.JiraIssue
+	fetchErrs  map[string]error
+}
+
+// queryProfileIssues fetches the issues of every repository using the profile, from the Jira of that profile.
+func queryProfileIssues(api network.JiraApi, repos []*cleanRepository, profile string) *profileIssues {
+	keys := make([]string, 0)
+	for _, repo := range repos {
+		if repo.Error == "" && repo.profile == profile {
+			keys = append(keys, slices.Concat(slices.Collect(maps.Values(repo.issues))...)...)
+		}
+	}
+
+	jiraIssues, fetchErrs := queryIssues(statusQuery(api), keys)
+	return &profileIssues{jiraIssues: jiraIssues, fetchErrs: fetchErrs}
 }
 
 func init() {
@@ -146,170 +289,292 @@ func init() {
 		"(optional) delete branch while ignoring the assignee; the 'assignee' option is disregarded when this flag is used",
 	)
 
+	cleanCmd.PersistentFlags().BoolVar(
+		&onlyMerged,
+		"merged",
+		false,
+		fmt.Sprintf(
+			"(optional) delete branch only if it is already merged into %s",
+			config.FromToken(config.BranchDefault),
+		),
+	)
+
+	cleanCmd.PersistentFlags().StringSliceVar(
+		&cleanRepos,
+		"repos",
+		nil,
+		"(optional) directories or globs of git repositories to clean at once",
+	)
+
+	cleanCmd.PersistentFlags().BoolVar(
+		&useWorkspace,
+		"workspace",
+		false,
+		fmt.Sprintf(
+			"(optional) clean every git repository listed in %s",
+			config.FromToken(config.WorkspaceRepos),
+		),
+	)
+
+	cleanCmd.PersistentFlags().StringVarP(
+		&cleanOutput,
+		"output",
+		"o",
+		outputText,
+		fmt.Sprintf("(optional) format of the final report: %s or %s", outputText, outputJson),
+	)
+
 	cleanCmd.AddCommand(
 		cleanLocalCmd,
 		cleanAllCmd,
 	)
 }
 
-func deleteBranchesIfAny(cmdName, remote string, statuses map[string]network.IssueStatusCategory) error {
-	anyInDoneStatus := false
+func validateCleanOutput() error {
+	switch cleanOutput {
+	case outputText, outputJson:
+		return nil
+	default:
+		return fmt.Errorf("validate: unsupported output %q", cleanOutput)
+	}
+}
 
-	for branchName, status := range statuses {
-		if status.Id == doneStatusId {
-			deleteLocalBranch(branchName)
+func deleteBranches(repo git.Repository, cmdName, remote, devBranch string, reports []*branchReport) {
+	for _, report := range reports {
+		if report.Outcome != outcomeDeleted {
+			continue
+		}
 
-			if cmdName == cleanAllCmdName {
-				deleteRemoteBranch(remote, branchName)
-			}
+		if onlyMerged && !common.IsMerged(repo, report.Branch, devBranch) {
+			report.skip(outcomeSkippedUnmerged, fmt.Sprintf("not merged into %q", devBranch))
+			continue
+		}
 
-			anyInDoneStatus = true
+		if err := deleteLocalBranch(repo, report.Branch); err != nil {
+			report.skip(outcomeDeleteError, err.Error())
+			continue
 		}
-	}
 
-	if !anyInDoneStatus {
-		return fmt.Errorf("no associated Jira issues in DONE status where assignee is %q", assignee)
+		if cmdName == cleanAllCmdName {
+			if err := deleteRemoteBranch(repo, remote, report.Branch); err != nil {
+				report.skip(outcomeDeleteError, err.Error())
+			}
+		}
 	}
-
-	return nil
 }
 
-func deleteLocalBranch(branchName string) {
-	deleteCommand, err := common.DeleteLocalBranch(branchName)
+func deleteLocalBranch(repo git.Repository, branchName string) error {
+	deleteCommand, err := common.DeleteLocalBranch(repo, branchName)
 	if err != nil {
 		log.Error().Print(deleteCommand)
-		log.Error().Print(fmt.Errorf("local branch: [%s] %w\n", branchName, err).Error())
-	} else {
-		log.Info().Print(deleteCommand)
+		return fmt.Errorf("local branch: [%s] %w", branchName, err)
 	}
+
+	log.Info().Print(deleteCommand)
+	return nil
 }
 
-func deleteRemoteBranch(remote, branchName string) {
-	deleteCommand, err := common.DeleteRemoteBranch(remote, branchName)
+func deleteRemoteBranch(repo git.Repository, remote, branchName string) error {
+	deleteCommand, err := common.DeleteRemoteBranch(repo, remote, branchName)
 	if err != nil {
 		log.Error().Print(deleteCommand)
-		log.Error().Print(fmt.Errorf("remote branch: [%s] %w\n", branchName, err).Error())
-	} else {
-		log.Info().Print(deleteCommand)
+		return fmt.Errorf("remote branch: [%s] %w", branchName, err)
 	}
+
+	log.Info().Print(deleteCommand)
+	return nil
 }
 
-func pairBranchesWithStatuses(api network.JiraApi, issues map[string]string) (map[string]network.IssueStatusCategory, error) {
-	statuses := make(map[string]network.IssueStatusCategory)
+// excludeProtectedBranches removes branches matching clean.protected before anything is fetched or deleted.
+func excludeProtectedBranches(issues map[string][]string, patterns []string) (map[string][]string, []*branchReport) {
+	allowed := make(map[string][]string)
+	reports := make([]*branchReport, 0)
 
-	size := len(issues)
-	if size <= itemsThreshold {
-		queryIssues(api, issues, statuses)
-	} else {
-		bulkQueryIssues(api, issues, statuses)
-	}
+	for localBranch, keys := range issues {
+		if !branch.IsProtected(localBranch, patterns) {
+			allowed[localBranch] = keys
+			continue
+		}
 
-	if len(statuses) == 0 {
-		return nil, errors.New("nothing to clean")
+		reports = append(reports, &branchReport{
+			Branch:  localBranch,
+			Issues:  keys,
+			Outcome: outcomeSkippedProtected,
+			Reason:  fmt.Sprintf("matches %q", config.FromToken(config.CleanProtected)),
+		})
 	}
 
-	return statuses, nil
+	return allowed, reports
 }
 
-func queryIssues(api network.JiraApi, issues map[string]string, statuses map[string]network.IssueStatusCategory) {
-	for localBranch, issue := range issues {
-		jiraIssue, err := api.GetJiraIssueStatus(issue, !ignoreAssignee)
-		if err != nil {
-			log.Debug().Println(fmt.Sprintf("Branch with status %s", err.Error()))
+// excludeCheckedOutBranches removes branches checked out in another worktree, git refuses to delete them.
+func excludeCheckedOutBranches(issues map[string][]string, localBranches []git.BranchInfo) (map[string][]string, []*branchReport) {
+	reports := make([]*branchReport, 0)
+
+	for _, localBranch := range localBranches {
+		keys, ok := issues[localBranch.Name]
+		if !ok || !localBranch.CheckedOutElsewhere() {
 			continue
 		}
 
-		hasJiraAssignee := jiraIssue.Fields.Assignee != nil
-		if !ignoreAssignee {
-			if !hasJiraAssignee {
-				log.Debug().Println(fmt.Sprintf("Issue %q is unassingned, skip", jiraIssue.Key))
-				continue
-			}
+		delete(issues, localBranch.Name)
+		reports = append(reports, &branchReport{
+			Branch:  localBranch.Name,
+			Issues:  keys,
+			Outcome: outcomeSkippedWorktree,
+			Reason:  fmt.Sprintf("checked out in %q", localBranch.Worktree),
+		})
+	}
 
-			email := jiraIssue.Fields.Assignee.Email
+	return issues, reports
+}
+
+// issueQuery fetches issues one by one or in batches, both with the same fields.
+type issueQuery struct {
+	single func(key string) (*network.JiraIssue, error)
+	bulk   func(fetcher *network.BulkFetcher, keys []string) ([]network.JiraIssue, error)
+}
 
-			if err = validateJiraIssue(jiraIssue.Key, email, assignee); err != nil {
-				log.Debug().Println(err.Error())
+// statusQuery fetches the status and, unless --any is set, the assignee needed by clean.
+func statusQuery(api network.JiraApi) issueQuery {
+	return issueQuery{
+		single: func(key string) (*network.JiraIssue, error) {
+			return api.GetJiraIssueStatus(key, !ignoreAssignee)
+		},
+		bulk: func(fetcher *network.BulkFetcher, keys []string) ([]network.JiraIssue, error) {
+			return fetcher.FetchStatuses(api, keys, !ignoreAssignee)
+		},
+	}
+}
+
+// queryIssues fetches every unique key and reports the keys which could not be fetched.
+func queryIssues(query issueQuery, keys []string) (map[string]network.JiraIssue, map[string]error) {
+	keys = slices.Sorted(slices.Values(keys))
+	keys = slices.Compact(keys)
+
+	jiraIssues := make(map[string]network.JiraIssue)
+	fetchErrs := make(map[string]error)
+
+	if len(keys) <= itemsThreshold {
+		for _, key := range keys {
+			jiraIssue, err := query.single(key)
+			if err != nil {
+				log.Debug().Println(fmt.Sprintf("Issue %q with status %s", key, err.Error()))
+				fetchErrs[key] = err
 				continue
 			}
+
+			jiraIssues[key] = *jiraIssue
 		}
 
-		log.Debug().Println(fmt.Sprintf("Branch %q with status %q", localBranch, jiraIssue.Fields.Status.Category.Name))
-		statuses[localBranch] = jiraIssue.Fields.Status.Category
+		return jiraIssues, fetchErrs
 	}
-}
 
-func bulkQueryIssues(api network.JiraApi, issues map[string]string, statuses map[string]network.IssueStatusCategory) {
-	size := len(issues)
+	limiter := network.NewTokenBucket(requestLimit, requestLimit)
+	fetcher := network.NewBulkFetcher(limiter, workersLimit, itemsPerRequest)
 
-	jiraIssues := make([]network.JiraIssue, 0)
-	values := slices.Collect(maps.Values(issues))
-	attemptsNeeded := calculateAttempts(size)
+	fetched, err := query.bulk(fetcher, keys)
+	if err != nil {
+		log.Debug().Println(fmt.Sprintf("Bulk issue: %s", err.Error()))
+		collectBatchErrors(err, fetchErrs)
+	}
 
-	var wg sync.WaitGroup
-	wg.Add(attemptsNeeded)
+	for _, jiraIssue := range fetched {
+		jiraIssues[jiraIssue.Key] = jiraIssue
+	}
 
-	for i := 0; i < attemptsNeeded; i++ {
-		go func(batch int) {
-			mu.Lock()
-			jiraIssues = append(jiraIssues, getJiraIssueStatusBulk(batch, api, values, !ignoreAssignee)...)
-			mu.Unlock()
+	for _, key := range keys {
+		_, fetched := jiraIssues[key]
+		_, failed := fetchErrs[key]
 
-			wg.Done()
-		}(i)
+		if !fetched && !failed {
+			fetchErrs[key] = errors.New("issue does not exist or you do not have permission to see it")
+		}
 	}
 
-	wg.Wait()
+	return jiraIssues, fetchErrs
+}
 
-	jiraKeyToIssueMap := make(map[string]network.JiraIssue)
-	for _, jiraIssue := range jiraIssues {
-		jiraKeyToIssueMap[jiraIssue.Key] = jiraIssue
+func collectBatchErrors(err error, fetchErrs map[string]error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			collectBatchErrors(e, fetchErrs)
+		}
+		return
 	}
 
-	for localBranch, issue := range issues {
-		jiraIssue := jiraKeyToIssueMap[issue]
+	var batchErr *network.BatchError
+	if errors.As(err, &batchErr) {
+		for _, key := range batchErr.Keys {
+			fetchErrs[key] = batchErr.Err
+		}
+	}
+}
 
-		hasJiraAssignee := jiraIssue.Fields.Assignee != nil
-		if !ignoreAssignee {
-			if !hasJiraAssignee {
-				log.Debug().Println(fmt.Sprintf("Issue %q is unassingned, skip", jiraIssue.Key))
-				continue
-			}
+// evaluateBranches decides for every branch whether it should be deleted.
+// A branch linked to several issues is deleted only when all of them pass.
+// Branches marked as deleted are only candidates until deleteBranches runs.
+func evaluateBranches(issues map[string][]string, user string, jiraIssues map[string]network.JiraIssue, fetchErrs map[string]error) []*branchReport {
+	reports := make([]*branchReport, 0, len(issues))
+
+	for localBranch, keys := range issues {
+		report := &branchReport{
+			Branch:  localBranch,
+			Issues:  keys,
+			Outcome: outcomeDeleted,
+		}
+		reports = append(reports, report)
 
-			email := jiraIssue.Fields.Assignee.Email
+		statuses := make([]string, 0, len(keys))
+		for _, key := range keys {
+			outcome, reason := evaluateIssue(key, user, jiraIssues, fetchErrs)
 
-			if err := validateJiraIssue(jiraIssue.Key, email, assignee); err != nil {
-				log.Debug().Println(err.Error())
-				continue
+			if jiraIssue, ok := jiraIssues[key]; ok && jiraIssue.Fields.Status != nil {
+				statuses = append(statuses, jiraIssue.Fields.Status.Category.Name)
+			}
+
+			// keep the first reason to skip, unless an issue could not be fetched at all
+			if outcome != outcomeDeleted && (report.Outcome == outcomeDeleted || outcome == outcomeFetchError) {
+				report.skip(outcome, reason)
 			}
 		}
 
-		log.Debug().Println(fmt.Sprintf("Branch %q with status %q", localBranch, jiraIssue.Fields.Status.Category.Name))
-		statuses[localBranch] = jiraIssue.Fields.Status.Category
+		report.Status = strings.Join(statuses, ",")
+		log.Debug().Println(fmt.Sprintf("Branch %q with status %q", localBranch, report.Status))
 	}
-}
 
-func getJiraIssueStatusBulk(batch int, api network.JiraApi, values []string, hasAssignee bool) []network.JiraIssue {
-	<-rate
+	return reports
+}
 
-	size := len(values)
+func evaluateIssue(key, user string, jiraIssues map[string]network.JiraIssue, fetchErrs map[string]error) (cleanOutcome, string) {
+	if err, ok := fetchErrs[key]; ok {
+		return outcomeFetchError, fmt.Sprintf("%s: %s", key, err.Error())
+	}
 
-	start := batch * itemsPerRequest
-	end := start + itemsPerRequest
-	if end > size {
-		end = size
+	jiraIssue, ok := jiraIssues[key]
+	if !ok || jiraIssue.Fields.Status == nil {
+		return outcomeFetchError, fmt.Sprintf("%s: issue status is missing", key)
 	}
 
-	jiraIssues, err := api.GetJiraIssueStatusBulk(values[start:end-1], hasAssignee)
-	if err != nil {
-		log.Debug().Println(fmt.Sprintf("Bulk issue: %s", err.Error()))
+	if !ignoreAssignee {
+		if jiraIssue.Fields.Assignee == nil {
+			log.Debug().Println(fmt.Sprintf("Issue %q is unassingned, skip", key))
+			return outcomeSkippedAssignee, fmt.Sprintf("%s: issue is unassigned", key)
+		}
+
+		email := jiraIssue.Fields.Assignee.Email
+
+		if err := validateJiraIssue(key, email, user); err != nil {
+			log.Debug().Println(err.Error())
+			return outcomeSkippedAssignee, err.Error()
+		}
 	}
 
-	return jiraIssues
-}
+	if jiraIssue.Fields.Status.Category.Id != doneStatusId {
+		return outcomeSkippedStatus, fmt.Sprintf("%s: issue is not done", key)
+	}
 
-func calculateAttempts(size int) int {
-	return (size + itemsPerRequest - 1) / itemsPerRequest
+	return outcomeDeleted, ""
 }
 
 func validateJiraIssue(issueKey, email, assignee string) error {
@@ -326,26 +591,34 @@ func validateJiraIssue(issueKey, email, assignee string) error {
 	return nil
 }
 
-func pairBranchesWithIssues(rawBranches string) (map[string]string, error) {
-	localBranches := strings.Split(rawBranches, "\n")
-	issues := make(map[string]string)
+// newIssueKeyBranch creates a Branch which extracts issue keys according to the [issue] config.
+func newIssueKeyBranch() (*branch.Branch, error) {
 	b := branch.New(branch.NULL, nil)
 
-	for _, localBranch := range localBranches {
-		trimmedBranchName := strings.Join(strings.Fields(localBranch), "")
+	err := b.SetKeyPattern(branch.KeyPattern{
+		Projects:   config.GetStringArray(config.IssueProjects),
+		Separators: config.GetStringArray(config.IssueSeparators),
+		Position:   config.GetString(config.IssuePosition),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config: %q %w", config.FromToken(config.IssuePosition), err)
+	}
+
+	return b, nil
+}
+
+func pairBranchesWithIssues(b *branch.Branch, localBranches []git.BranchInfo) map[string][]string {
+	issues := make(map[string][]string)
 
-		issue, err := b.ExtractIssueNameFromBranch(trimmedBranchName)
-		if err != nil || issue == "" {
+	for _, localBranch := range localBranches {
+		keys, err := b.ExtractIssueKeysFromBranch(localBranch.Name)
+		if err != nil || len(keys) == 0 {
 			continue
 		}
 
-		log.Debug().Println(fmt.Sprintf("Branch %q with issue %q", issue, trimmedBranchName))
-		issues[trimmedBranchName] = issue
-	}
-
-	if len(issues) == 0 {
-		return nil, fmt.Errorf("no branches related to Jira issues were found")
+		log.Debug().Println(fmt.Sprintf("Branch %q with issues %q", localBranch.Name, keys))
+		issues[localBranch.Name] = keys
 	}
 
-	return issues, nil
+	return issues
 }
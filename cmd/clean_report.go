@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"twig/log"
+)
+
+const (
+	outputText = "text"
+	outputJson = "json"
+)
+
+type cleanOutcome string
+
+const (
+	outcomeDeleted          cleanOutcome = "deleted"
+	outcomeSkippedAssignee  cleanOutcome = "skipped-assignee"
+	outcomeSkippedStatus    cleanOutcome = "skipped-status"
+	outcomeSkippedUnmerged  cleanOutcome = "skipped-unmerged"
+	outcomeSkippedProtected cleanOutcome = "skipped-protected"
+	outcomeSkippedWorktree  cleanOutcome = "skipped-worktree"
+	outcomeFetchError       cleanOutcome = "fetch-error"
+	outcomeDeleteError      cleanOutcome = "delete-error"
+)
+
+type branchReport struct {
+	Branch  string       `json:"branch"`
+	Issues  []string     `json:"issues"`
+	Status  string       `json:"status,omitempty"`
+	Outcome cleanOutcome `json:"outcome"`
+	Reason  string       `json:"reason,omitempty"`
+}
+
+func (r *branchReport) skip(outcome cleanOutcome, reason string) {
+	r.Outcome = outcome
+	r.Reason = reason
+}
+
+func countOutcome(reports []*branchReport, outcome cleanOutcome) int {
+	count := 0
+	for _, report := range reports {
+		if report.Outcome == outcome {
+			count++
+		}
+	}
+
+	return count
+}
+
+func printCleanReport(reports []*branchReport) error {
+	if cleanOutput == outputJson {
+		return printJson(reports)
+	}
+
+	table, err := renderCleanTable(reports)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Print(fmt.Sprintf("\n%s", table))
+	printCleanSummary(reports)
+
+	return nil
+}
+
+func printWorkspaceReport(repos []*cleanRepository) error {
+	if cleanOutput == outputJson {
+		return printJson(repos)
+	}
+
+	all := make([]*branchReport, 0)
+
+	for _, repo := range repos {
+		log.Info().Println(fmt.Sprintf("\nRepository %q", repo.Path))
+
+		if repo.Error != "" {
+			log.Error().Println(repo.Error)
+			continue
+		}
+
+		table, err := renderCleanTable(repo.Branches)
+		if err != nil {
+			return err
+		}
+
+		log.Info().Print(table)
+		all = append(all, repo.Branches...)
+	}
+
+	log.Info().Println()
+	printCleanSummary(all)
+	log.Info().Println(fmt.Sprintf("Repositories: %d cleaned, %d failed", len(repos)-countFailedRepositories(repos), countFailedRepositories(repos)))
+
+	return nil
+}
+
+func printJson(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+func renderCleanTable(reports []*branchReport) (string, error) {
+	var buffer strings.Builder
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "BRANCH\tISSUES\tSTATUS\tOUTCOME\tREASON")
+	for _, r := range reports {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Branch, strings.Join(r.Issues, ","), r.Status, r.Outcome, r.Reason)
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func printCleanSummary(reports []*branchReport) {
+	log.Info().Println(fmt.Sprintf(
+		"Summary: %d deleted, %d skipped, %d failed to fetch, %d failed to delete",
+		countOutcome(reports, outcomeDeleted),
+		countOutcome(reports, outcomeSkippedAssignee)+
+			countOutcome(reports, outcomeSkippedStatus)+
+			countOutcome(reports, outcomeSkippedUnmerged)+
+			countOutcome(reports, outcomeSkippedProtected)+
+			countOutcome(reports, outcomeSkippedWorktree),
+		countOutcome(reports, outcomeFetchError),
+		countOutcome(reports, outcomeDeleteError),
+	))
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what the function printed to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	t.Cleanup(func() {
+		os.Stdout = stdout
+	})
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(out)
+}
+
+func useCleanOutput(t *testing.T, output string) {
+	t.Cleanup(func() {
+		cleanOutput = outputText
+	})
+
+	cleanOutput = output
+}
+
+var testReports = []*branchReport{
+	{Branch: "feat/ABC-1_done", Issues: []string{"ABC-1"}, Status: "done", Outcome: outcomeDeleted},
+	{Branch: "feat/ABC-2_todo", Issues: []string{"ABC-2"}, Status: "indeterminate", Outcome: outcomeSkippedStatus, Reason: "ABC-2: issue is not done"},
+	{Branch: "feat/ABC-3_ABC-4_combo", Issues: []string{"ABC-3", "ABC-4"}, Outcome: outcomeFetchError, Reason: "ABC-4: search failed"},
+}
+
+func TestPrintCleanReportJson(t *testing.T) {
+	useCleanOutput(t, outputJson)
+
+	subject := captureStdout(t, func() {
+		if err := printCleanReport(testReports); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// scripts rely on the field names and omitted empty values
+	want := `[
+  {
+    "branch": "feat/ABC-1_done",
+    "issues": [
+      "ABC-1"
+    ],
+    "status": "done",
+    "outcome": "deleted"
+  },
+  {
+    "branch": "feat/ABC-2_todo",
+    "issues": [
+      "ABC-2"
+    ],
+    "status": "indeterminate",
+    "outcome": "skipped-status",
+    "reason": "ABC-2: issue is not done"
+  },
+  {
+    "branch": "feat/ABC-3_ABC-4_combo",
+    "issues": [
+      "ABC-3",
+      "ABC-4"
+    ],
+    "outcome": "fetch-error",
+    "reason": "ABC-4: search failed"
+  }
+]
+`
+	if subject != want {
+		t.Errorf(`printCleanReport(json) = %s, want match for %s`, subject, want)
+	}
+}
+
+func TestPrintCleanReportText(t *testing.T) {
+	useCleanOutput(t, outputText)
+
+	subject := captureStdout(t, func() {
+		if err := printCleanReport(testReports); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// the table is logged, stdout is left to --output json
+	if subject != "" {
+		t.Errorf(`printCleanReport(text) printed %q to stdout, want nothing`, subject)
+	}
+}
+
+func TestRenderCleanTable(t *testing.T) {
+	subject, err := renderCleanTable(testReports)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"BRANCH                  ISSUES       STATUS         OUTCOME         REASON",
+		"feat/ABC-1_done         ABC-1        done           deleted         ",
+		"feat/ABC-2_todo         ABC-2        indeterminate  skipped-status  ABC-2: issue is not done",
+		"feat/ABC-3_ABC-4_combo  ABC-3,ABC-4                 fetch-error     ABC-4: search failed",
+	}
+	if lines := strings.Split(strings.TrimSuffix(subject, "\n"), "\n"); !slices.Equal(lines, want) {
+		t.Errorf(`renderCleanTable() = %q, want match for %q`, lines, want)
+	}
+}
+
+func TestCountOutcome(t *testing.T) {
+	if subject := countOutcome(testReports, outcomeFetchError); subject != 1 {
+		t.Errorf(`countOutcome(fetch-error) = %d, want 1`, subject)
+	}
+
+	if subject := countOutcome(testReports, outcomeSkippedAssignee); subject != 0 {
+		t.Errorf(`countOutcome(skipped-assignee) = %d, want 0`, subject)
+	}
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"twig/git"
+	"twig/git/gittest"
+	"twig/network"
+)
+
+func newDoneIssue(key string, statusId int, email string) network.JiraIssue {
+	jiraIssue := newJiraIssue(key, "10001", key)
+	jiraIssue.Fields.Status = &network.IssueStatus{Category: network.IssueStatusCategory{Id: statusId}}
+	jiraIssue.Fields.Assignee = &network.IssueAssignee{Email: email}
+
+	return jiraIssue
+}
+
+func useCleanFlags(t *testing.T, merged bool) {
+	t.Cleanup(func() {
+		assignee, ignoreAssignee, onlyMerged = "", false, false
+	})
+
+	assignee, ignoreAssignee, onlyMerged = "", false, merged
+}
+
+func TestRunCleanLocal(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useCleanFlags(t, false)
+
+	repo := gittest.New("feat/ABC-2_todo", "development", "feat/ABC-1_done", "feat/ABC-5_other", "hotfix/ABC-3_done")
+	repo.Remote["origin"] = []string{"feat/ABC-1_done"}
+
+	useFakes(t, repo, newFakeJiraApi(
+		newDoneIssue("ABC-1", doneStatusId, "john.doe@example.com"),
+		newDoneIssue("ABC-2", 2, "john.doe@example.com"),
+		newDoneIssue("ABC-3", doneStatusId, "john.doe@example.com"),
+		newDoneIssue("ABC-5", doneStatusId, "jane.roe@example.com"),
+	))
+
+	runClean(cleanLocalCmd, nil)
+
+	want := []string{"fetch -p origin", "checkout development", "branch -D feat/ABC-1_done"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runClean(local) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestRunCleanAllMerged(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useCleanFlags(t, true)
+
+	repo := gittest.New("development", "feat/ABC-1_merged", "feat/ABC-4_unmerged")
+	repo.Remote["origin"] = []string{"development", "feat/ABC-1_merged", "feat/ABC-4_unmerged"}
+	repo.Merged["feat/ABC-1_merged"] = []string{"development"}
+
+	useFakes(t, repo, newFakeJiraApi(
+		newDoneIssue("ABC-1", doneStatusId, "john.doe@example.com"),
+		newDoneIssue("ABC-4", doneStatusId, "john.doe@example.com"),
+	))
+
+	runClean(cleanAllCmd, nil)
+
+	want := []string{"fetch -p origin", "checkout development", "branch -D feat/ABC-1_merged", "push -d origin feat/ABC-1_merged"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runClean(all) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestRunCleanSkipsWorktreeBranches(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useCleanFlags(t, false)
+
+	repo := gittest.New("development", "feat/ABC-1_done", "feat/ABC-6_worktree")
+	repo.Info["feat/ABC-6_worktree"] = git.BranchInfo{Worktree: "/src/twig-worktree"}
+
+	useFakes(t, repo, newFakeJiraApi(
+		newDoneIssue("ABC-1", doneStatusId, "john.doe@example.com"),
+		newDoneIssue("ABC-6", doneStatusId, "john.doe@example.com"),
+	))
+
+	runClean(cleanLocalCmd, nil)
+
+	want := []string{"fetch -p origin", "checkout development", "branch -D feat/ABC-1_done"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runClean(local) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestPrepareCleanRepositoryDirty(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("feat/ABC-1_done", "development")
+	repo.Dirty = true
+	useFakes(t, repo, newFakeJiraApi())
+
+	err := prepareCleanRepository(&cleanRepository{})
+
+	want := []string{"fetch -p origin"}
+	if err == nil || !slices.Equal(repo.Commands, want) {
+		t.Errorf(`prepareCleanRepository() = %v %q, want error and %q`, err, repo.Commands, want)
+	}
+}
+
+// failingBulkApi fails every batch request, like a search rejected by Jira.
+type failingBulkApi struct {
+	*fakeJiraApi
+}
+
+func (api failingBulkApi) GetJiraIssueStatusBulk(issueKeys []string, hasAssignee bool) ([]network.JiraIssue, error) {
+	return nil, errors.New("search failed")
+}
+
+// cleanReports runs clean local on the current repository up to the report.
+func cleanReports(t *testing.T, api network.JiraApi) map[string]*branchReport {
+	repo := &cleanRepository{}
+	if err := prepareCleanRepository(repo); err != nil {
+		t.Fatal(err)
+	}
+
+	jiraIssues, fetchErrs := queryIssues(statusQuery(api), slices.Concat(slices.Collect(maps.Values(repo.issues))...))
+	finishCleanRepository(cleanLocalCmdName, repo, jiraIssues, fetchErrs)
+
+	reports := make(map[string]*branchReport)
+	for _, report := range repo.Branches {
+		reports[report.Branch] = report
+	}
+
+	return reports
+}
+
+func TestCleanOutcomes(t *testing.T) {
+	batch := []string{"feat/ABC-1_a", "feat/ABC-2_b", "feat/ABC-3_c", "feat/ABC-4_d", "feat/ABC-5_e", "feat/ABC-6_f"}
+
+	tests := []struct {
+		name     string
+		branches []string
+		api      network.JiraApi
+		want     cleanOutcome
+		reason   string
+	}{
+		{
+			name:     "deleted",
+			branches: []string{"feat/ABC-1_done"},
+			api:      newFakeJiraApi(newDoneIssue("ABC-1", doneStatusId, "john.doe@example.com")),
+			want:     outcomeDeleted,
+		},
+		{
+			name:     "skipped-assignee",
+			branches: []string{"feat/ABC-1_other"},
+			api:      newFakeJiraApi(newDoneIssue("ABC-1", doneStatusId, "jane.roe@example.com")),
+			want:     outcomeSkippedAssignee,
+			reason:   `has assignee "jane.roe"`,
+		},
+		{
+			name:     "skipped-status",
+			branches: []string{"feat/ABC-1_todo"},
+			api:      newFakeJiraApi(newDoneIssue("ABC-1", 2, "john.doe@example.com")),
+			want:     outcomeSkippedStatus,
+			reason:   "ABC-1: issue is not done",
+		},
+		{
+			name:     "protected",
+			branches: []string{"hotfix/ABC-1_done"},
+			api:      newFakeJiraApi(newDoneIssue("ABC-1", doneStatusId, "john.doe@example.com")),
+			want:     outcomeSkippedProtected,
+			reason:   `matches "clean.protected"`,
+		},
+		{
+			name:     "fetch-error",
+			branches: []string{"feat/ABC-1_missing"},
+			api:      newFakeJiraApi(),
+			want:     outcomeFetchError,
+			reason:   "ABC-1: issue does not exist",
+		},
+		{
+			// more keys than itemsThreshold are fetched in batches
+			name:     "fetch-error of a batch",
+			branches: batch,
+			api:      failingBulkApi{newFakeJiraApi(newDoneIssue("ABC-1", doneStatusId, "john.doe@example.com"))},
+			want:     outcomeFetchError,
+			reason:   "search failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, testConfig)
+			useCleanFlags(t, false)
+
+			repo := gittest.New(append([]string{"development"}, tt.branches...)...)
+			useFakes(t, repo, tt.api)
+
+			reports := cleanReports(t, tt.api)
+
+			for _, name := range tt.branches {
+				report, ok := reports[name]
+				if !ok {
+					t.Fatalf(`clean reports = %v, want a report of %q`, reports, name)
+				}
+
+				if report.Outcome != tt.want || !strings.Contains(report.Reason, tt.reason) {
+					t.Errorf(`report of %q = %s %q, want match for %s %q`, name, report.Outcome, report.Reason, tt.want, tt.reason)
+				}
+			}
+
+			deleted := slices.ContainsFunc(repo.Commands, func(command string) bool {
+				return strings.HasPrefix(command, "branch -D")
+			})
+			if deleted != (tt.want == outcomeDeleted) {
+				t.Errorf(`clean = %q, want branches deleted only for %s`, repo.Commands, outcomeDeleted)
+			}
+		})
+	}
+}
+
+func TestCollectBatchErrors(t *testing.T) {
+	searchErr := errors.New("search failed")
+	limitErr := errors.New("rate limited")
+
+	err := errors.Join(
+		&network.BatchError{Keys: []string{"ABC-1", "ABC-2"}, Err: searchErr},
+		fmt.Errorf("bulk: %w", &network.BatchError{Keys: []string{"ABC-3"}, Err: limitErr}),
+		errors.New("no batch"),
+	)
+
+	fetchErrs := make(map[string]error)
+	collectBatchErrors(err, fetchErrs)
+
+	want := map[string]error{"ABC-1": searchErr, "ABC-2": searchErr, "ABC-3": limitErr}
+	if !maps.Equal(fetchErrs, want) {
+		t.Errorf(`collectBatchErrors() = %v, want match for %v`, fetchErrs, want)
+	}
+}
+
+func TestEvaluateBranchesSeveralIssues(t *testing.T) {
+	useCleanFlags(t, false)
+
+	jiraIssues := map[string]network.JiraIssue{
+		"ABC-1": newDoneIssue("ABC-1", doneStatusId, "john.doe@example.com"),
+		"ABC-2": newDoneIssue("ABC-2", 2, "john.doe@example.com"),
+		"ABC-3": newDoneIssue("ABC-3", 2, "jane.roe@example.com"),
+	}
+	fetchErrs := map[string]error{"ABC-4": errors.New("search failed")}
+
+	tests := []struct {
+		keys   []string
+		want   cleanOutcome
+		reason string
+	}{
+		{keys: []string{"ABC-1"}, want: outcomeDeleted},
+		{keys: []string{"ABC-1", "ABC-2"}, want: outcomeSkippedStatus, reason: "ABC-2: issue is not done"},
+		{keys: []string{"ABC-2", "ABC-3"}, want: outcomeSkippedStatus, reason: "ABC-2: issue is not done"},
+		{keys: []string{"ABC-2", "ABC-4"}, want: outcomeFetchError, reason: "ABC-4: search failed"},
+	}
+
+	for _, tt := range tests {
+		reports := evaluateBranches(map[string][]string{"feat/combo": tt.keys}, "john.doe", jiraIssues, fetchErrs)
+
+		if len(reports) != 1 || reports[0].Outcome != tt.want || reports[0].Reason != tt.reason {
+			t.Errorf(`evaluateBranches(%q) = %+v, want match for %s %q`, tt.keys, reports[0], tt.want, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/mitchellh/go-homedir"
+	"os"
+	"path/filepath"
+	"slices"
+	"twig/config"
+	"twig/git"
+	"twig/log"
+)
+
+type cleanRepository struct {
+	Path     string          `json:"repository"`
+	Error    string          `json:"error,omitempty"`
+	Branches []*branchReport `json:"branches"`
+
+	profile   string
+	user      string
+	git       git.Repository
+	remote    string
+	devBranch string
+	issues    map[string][]string
+}
+
+// discoverCleanRepositories resolves --repos or, with --workspace, workspace.repos into git repositories.
+// Every entry is either a repository, a directory with repositories or a glob of them.
+func discoverCleanRepositories() ([]string, error) {
+	patterns := cleanRepos
+	if useWorkspace {
+		patterns = append(patterns, config.GetStringArray(config.WorkspaceRepos)...)
+
+		if len(patterns) == 0 {
+			return nil, fmt.Errorf("%q is not set", config.FromToken(config.WorkspaceRepos))
+		}
+	}
+
+	repos := make([]string, 0)
+
+	for _, pattern := range patterns {
+		expanded, err := homedir.Expand(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		matches, err := filepath.Glob(expanded)
+		if err != nil {
+			return nil, fmt.Errorf("repos: %q %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			found, err := findGitRepositories(match)
+			if err != nil {
+				return nil, err
+			}
+
+			repos = append(repos, found...)
+		}
+	}
+
+	if len(patterns) > 0 && len(repos) == 0 {
+		return nil, fmt.Errorf("no git repositories found in %q", patterns)
+	}
+
+	slices.Sort(repos)
+	return slices.Compact(repos), nil
+}
+
+func findGitRepositories(dir string) ([]string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+
+	if isGitRepository(abs) {
+		return []string{abs}, nil
+	}
+
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make([]string, 0)
+	for _, entry := range entries {
+		child := filepath.Join(abs, entry.Name())
+
+		if entry.IsDir() && isGitRepository(child) {
+			repos = append(repos, child)
+		}
+	}
+
+	log.Debug().Println(fmt.Sprintf("Found %d repositories in %q", len(repos), abs))
+	return repos, nil
+}
+
+func isGitRepository(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// inRepository runs fn with the repository as working directory and its config layers loaded,
+// git commands run where twig runs.
+func inRepository(dir string, fn func() error) error {
+	if dir == "" {
+		return fn()
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	if err = os.Chdir(dir); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			log.Error().Println(fmt.Errorf("repos: %w", err))
+		}
+
+		if err := config.Reload(); err != nil {
+			log.Error().Println(fmt.Errorf("repos: %w", err))
+		}
+	}()
+
+	// the repository may have its own .twig.toml
+	if err = config.Reload(); err != nil {
+		return err
+	}
+
+	return fn()
+}
+
+func countFailedRepositories(repos []*cleanRepository) int {
+	count := 0
+	for _, repo := range repos {
+		if repo.Error != "" {
+			count++
+		}
+	}
+
+	return count
+}
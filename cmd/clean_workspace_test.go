@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"twig/config"
+	"twig/git"
+	"twig/git/gittest"
+	"twig/network"
+)
+
+// newWorkspace creates the directory root with a repository for every name, names ending with / are plain directories.
+func newWorkspace(t *testing.T, names ...string) string {
+	root := t.TempDir()
+
+	for _, name := range names {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if name[len(name)-1] == '/' {
+			continue
+		}
+
+		if _, err := gogit.PlainInit(dir, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func useWorkspaceFlags(t *testing.T, repos []string, workspace bool) {
+	t.Cleanup(func() {
+		cleanRepos, useWorkspace = nil, false
+	})
+
+	cleanRepos, useWorkspace = repos, workspace
+}
+
+func TestDiscoverCleanRepositories(t *testing.T) {
+	root := newWorkspace(t, "api", "web", "docs/", "libs/auth")
+
+	tests := []struct {
+		name      string
+		repos     []string
+		workspace []string
+		want      []string
+	}{
+		{
+			name:  "glob",
+			repos: []string{filepath.Join(root, "*")},
+			want:  []string{"api", "libs/auth", "web"},
+		},
+		{
+			name:  "directory",
+			repos: []string{root},
+			want:  []string{"api", "web"},
+		},
+		{
+			name:  "repository",
+			repos: []string{filepath.Join(root, "libs", "auth")},
+			want:  []string{"libs/auth"},
+		},
+		{
+			name:      "duplicates",
+			repos:     []string{filepath.Join(root, "api"), filepath.Join(root, "a*")},
+			workspace: []string{root, filepath.Join(root, "libs")},
+			want:      []string{"api", "libs/auth", "web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toml := testConfig
+			if tt.workspace != nil {
+				// a JSON array of strings is a TOML array as well
+				repos, _ := json.Marshal(tt.workspace)
+				toml += fmt.Sprintf("\n[workspace]\nrepos = %s\n", repos)
+			}
+
+			useTestConfig(t, toml)
+			useWorkspaceFlags(t, tt.repos, tt.workspace != nil)
+
+			subject, err := discoverCleanRepositories()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := make([]string, len(tt.want))
+			for i, name := range tt.want {
+				want[i] = filepath.Join(root, name)
+			}
+
+			if !slices.Equal(subject, want) {
+				t.Errorf(`discoverCleanRepositories() = %q, want match for %q`, subject, want)
+			}
+		})
+	}
+}
+
+func TestDiscoverCleanRepositoriesNone(t *testing.T) {
+	root := newWorkspace(t, "docs/")
+
+	tests := []struct {
+		name      string
+		repos     []string
+		workspace bool
+	}{
+		{name: "directory without repositories", repos: []string{root}},
+		{name: "glob without match", repos: []string{filepath.Join(root, "missing-*")}},
+		{name: "workspace without workspace.repos", workspace: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, testConfig)
+			useWorkspaceFlags(t, tt.repos, tt.workspace)
+
+			if subject, err := discoverCleanRepositories(); err == nil {
+				t.Errorf(`discoverCleanRepositories() = %q, want error`, subject)
+			}
+		})
+	}
+}
+
+func TestDiscoverCleanRepositoriesCurrent(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useWorkspaceFlags(t, nil, false)
+
+	// without --repos and --workspace only the current repository is cleaned
+	if subject, err := discoverCleanRepositories(); len(subject) != 0 || err != nil {
+		t.Errorf(`discoverCleanRepositories() = %q %v, want none`, subject, err)
+	}
+}
+
+func TestInRepository(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	dir := filepath.Join(newWorkspace(t, "api"), "api")
+	if err := os.WriteFile(filepath.Join(dir, ".twig.toml"), []byte("[branch]\ndefault = \"trunk\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("clean failed")
+	err = inRepository(dir, func() error {
+		if subject, _ := os.Getwd(); subject != dir {
+			t.Errorf(`working directory = %q, want match for %q`, subject, dir)
+		}
+
+		if subject := config.GetString(config.BranchDefault); subject != "trunk" {
+			t.Errorf(`GetString(BranchDefault) = %q, want "trunk" of the repository`, subject)
+		}
+
+		return failed
+	})
+
+	if !errors.Is(err, failed) {
+		t.Errorf(`inRepository() = %v, want match for %v`, err, failed)
+	}
+
+	if subject, _ := os.Getwd(); subject != wd {
+		t.Errorf(`working directory = %q, want %q restored`, subject, wd)
+	}
+
+	if subject := config.GetString(config.BranchDefault); subject != "development" {
+		t.Errorf(`GetString(BranchDefault) = %q, want "development" reloaded`, subject)
+	}
+}
+
+func TestRunCleanProfiles(t *testing.T) {
+	root := newWorkspace(t, "api", "web", "contract")
+
+	useTestConfig(t, testConfig+fmt.Sprintf(`
+[profiles.acme]
+host = "acme.atlassian.net"
+email = "jane.roe@acme.com"
+paths = [%q]
+`, filepath.Join(root, "contract*")))
+	useCleanFlags(t, false)
+	useWorkspaceFlags(t, []string{root}, false)
+
+	repos := make(map[string]*gittest.Repository)
+	for _, name := range []string{"api", "web", "contract"} {
+		repos[filepath.Join(root, name)] = gittest.New("development", "feat/ABC-1_done")
+	}
+
+	// ABC-1 exists in both Jira instances, assigned to the user of each profile
+	apis := map[string]network.JiraApi{
+		"":                   newFakeJiraApi(newDoneIssue("ABC-1", doneStatusId, "john.doe@example.com")),
+		"acme.atlassian.net": newFakeJiraApi(newDoneIssue("ABC-1", doneStatusId, "jane.roe@acme.com")),
+	}
+
+	hosts := make([]string, 0)
+	open, newApi := openRepository, newJiraApi
+	t.Cleanup(func() {
+		openRepository, newJiraApi = open, newApi
+	})
+
+	openRepository = func() (git.Repository, error) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return repos[wd], nil
+	}
+	newJiraApi = func() network.JiraApi {
+		host := config.GetString(config.ProjectHost)
+		hosts = append(hosts, host)
+
+		return apis[host]
+	}
+
+	runClean(cleanLocalCmd, nil)
+
+	if want := []string{"", "acme.atlassian.net"}; !slices.Equal(hosts, want) {
+		t.Errorf(`newJiraApi() hosts = %q, want match for %q`, hosts, want)
+	}
+
+	want := []string{"fetch -p origin", "checkout development", "branch -D feat/ABC-1_done"}
+	for dir, repo := range repos {
+		if !slices.Equal(repo.Commands, want) {
+			t.Errorf(`runClean(%s) = %q, want match for %q`, filepath.Base(dir), repo.Commands, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"strings"
+	"twig/commit"
+	"twig/log"
+)
+
+var (
+	commitMessages []string
+	commitRefs     bool
+	commitSmart    commit.SmartCommit
+	commitCmdName  = "commit"
+	commitCmd      = &cobra.Command{
+		Use:         commitCmdName,
+		Short:       "Commits with a message formatted after commit.template, further options after -- go to git commit",
+		Run:         runCommit,
+		Annotations: map[string]string{annotationRequiresGit: "true"},
+	}
+)
+
+func init() {
+	commitCmd.Flags().StringArrayVarP(
+		&commitMessages,
+		"message",
+		"m",
+		nil,
+		"commit message, formatted with the type and issue key of the branch; repeat it for more paragraphs like git commit",
+	)
+
+	commitCmd.Flags().BoolVar(
+		&commitRefs,
+		"refs",
+		false,
+		fmt.Sprintf("(optional) append the %q trailer", commit.Trailer("<issue-key>")),
+	)
+
+	commitCmd.Flags().StringVar(
+		&commitSmart.Comment,
+		"comment",
+		"",
+		"(optional) Jira smart commit: add the comment to the issue",
+	)
+
+	commitCmd.Flags().StringVar(
+		&commitSmart.Time,
+		"time",
+		"",
+		"(optional) Jira smart commit: log work on the issue, e.g. 1h 30m",
+	)
+
+	commitCmd.Flags().StringVar(
+		&commitSmart.Transition,
+		"transition",
+		"",
+		"(optional) Jira smart commit: move the issue through the workflow, e.g. resolve",
+	)
+
+	_ = commitCmd.MarkFlagRequired("message")
+}
+
+func runCommit(cmd *cobra.Command, args []string) {
+	log.Debug().Println("commit: executing command")
+
+	repo, err := openRepository()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	branchType, key, template, err := branchCommitContext(repo)
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	message := buildCommitMessage(template, branchType, key, commitMessages)
+	log.Debug().Println(fmt.Sprintf("Commit message %q", message))
+
+	out, err := repo.Commit(message, args...)
+	if out != "" {
+		log.Info().Println(out)
+	}
+
+	if err != nil {
+		logCmdFatal(err)
+	}
+}
+
+// buildCommitMessage formats the first paragraph and appends the smart commit commands and,
+// with --refs, the trailer, which has to be the last paragraph.
+func buildCommitMessage(template *commit.Template, branchType, key string, messages []string) string {
+	paragraphs := make([]string, 0, len(messages)+2)
+
+	for i, message := range messages {
+		if i == 0 {
+			message = template.Apply(branchType, key, strings.TrimSpace(message))
+		}
+
+		paragraphs = append(paragraphs, message)
+	}
+
+	if smart := commitSmart.Format(key); smart != "" {
+		paragraphs = append(paragraphs, smart)
+	}
+
+	if commitRefs {
+		paragraphs = append(paragraphs, commit.Trailer(key))
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
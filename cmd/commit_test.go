@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"twig/commit"
+	"twig/git/gittest"
+)
+
+func useCommitFlags(t *testing.T, messages []string, refs bool, smart commit.SmartCommit) {
+	t.Cleanup(func() {
+		commitMessages, commitRefs, commitSmart = nil, false, commit.SmartCommit{}
+	})
+
+	commitMessages, commitRefs, commitSmart = messages, refs, smart
+}
+
+func TestRunCommit(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useCommitFlags(t, []string{"handle null token"}, false, commit.SmartCommit{})
+
+	repo := gittest.New("fix/ABC-123_handle-null-token")
+	useFakes(t, repo, newFakeJiraApi())
+
+	runCommit(commitCmd, []string{"--no-verify"})
+
+	want := []string{"fix(ABC-123): handle null token"}
+	if !slices.Equal(repo.Messages, want) || !slices.Equal(repo.Commands, []string{"commit --no-verify"}) {
+		t.Errorf(`runCommit() = %q %q, want match for %q`, repo.Messages, repo.Commands, want)
+	}
+}
+
+func TestRunCommitWithTrailerAndSmartCommit(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useCommitFlags(t, []string{"handle null token", "The token may be missing."}, true, commit.SmartCommit{Comment: "fixed", Time: "1h"})
+
+	repo := gittest.New("fix/ABC-123_handle-null-token")
+	useFakes(t, repo, newFakeJiraApi())
+
+	runCommit(commitCmd, nil)
+
+	want := []string{"fix(ABC-123): handle null token\n\nThe token may be missing.\n\nABC-123 #time 1h #comment fixed\n\nRefs: ABC-123"}
+	if !slices.Equal(repo.Messages, want) {
+		t.Errorf(`runCommit() = %q, want match for %q`, repo.Messages, want)
+	}
+}
+
+func TestRunCommitKeepsFormattedMessage(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useCommitFlags(t, []string{"fix(ABC-123): handle null token"}, false, commit.SmartCommit{})
+
+	repo := gittest.New("fix/ABC-123_handle-null-token")
+	useFakes(t, repo, newFakeJiraApi())
+
+	runCommit(commitCmd, nil)
+
+	want := []string{"fix(ABC-123): handle null token"}
+	if !slices.Equal(repo.Messages, want) {
+		t.Errorf(`runCommit() = %q, want match for %q`, repo.Messages, want)
+	}
+}
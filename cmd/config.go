@@ -2,21 +2,31 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/c-bata/go-prompt"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
 	"strings"
 	"twig/config"
 	"twig/log"
+	"twig/secret"
 )
 
 var (
-	configCmd = &cobra.Command{
+	showSources   bool
+	setLocal      bool
+	migrateDryRun bool
+	configCmd     = &cobra.Command{
 		Use:   "config",
 		Short: "You can query/set/replace options with this command. The name is the section and the key separated by a dot",
 		Args:  cobra.NoArgs,
 	}
 	configListCmd = &cobra.Command{
 		Use:   "list",
-		Short: "List all variables set in config file, along with their values",
+		Short: "List all variables of the merged config layers, along with their values",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			cfg, err := config.GetAllSnapshot()
@@ -24,26 +34,141 @@ var (
 				logCmdFatal(err)
 			}
 
-			printString(config.ProjectHost, cfg.Project.Host)
-			printString(config.ProjectAuth, cfg.Project.Auth)
-			printString(config.ProjectEmail, cfg.Project.Email)
-			printString(config.ProjectToken, cfg.Project.Token)
-
-			printString(config.BranchDefault, cfg.Branch.Default)
-			printString(config.BranchOrigin, cfg.Branch.Origin)
-			printStringArr(config.BranchExclude, cfg.Branch.Exclude)
-
-			printStringArr(config.MappingBuild, cfg.Mapping.Build)
-			printStringArr(config.MappingChore, cfg.Mapping.Chore)
-			printStringArr(config.MappingCi, cfg.Mapping.Ci)
-			printStringArr(config.MappingDocs, cfg.Mapping.Docs)
-			printStringArr(config.MappingFeat, cfg.Mapping.Feat)
-			printStringArr(config.MappingFix, cfg.Mapping.Fix)
-			printStringArr(config.MappingPref, cfg.Mapping.Pref)
-			printStringArr(config.MappingRefactor, cfg.Mapping.Refactor)
-			printStringArr(config.MappingRevert, cfg.Mapping.Revert)
-			printStringArr(config.MappingStyle, cfg.Mapping.Style)
-			printStringArr(config.MappingTest, cfg.Mapping.Test)
+			if showSources {
+				for _, layer := range config.Layers() {
+					log.Info().Println(fmt.Sprintf("Layer %s", layer))
+				}
+			}
+
+			for _, key := range config.Keys() {
+				printKey(key)
+			}
+
+			for _, name := range slices.Sorted(maps.Keys(cfg.Profiles)) {
+				printProfile(name, cfg.Profiles[name])
+			}
+		},
+	}
+	configValidateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Checks the merged config for invalid values without contacting Jira",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			problems := config.Validate()
+			if len(problems) == 0 {
+				log.Info().Println("Config is valid")
+				return
+			}
+
+			printProblems(problems)
+			logCmdFatal(fmt.Errorf("config has %d problem(s)", len(problems)))
+		},
+	}
+	configUseCmd = &cobra.Command{
+		Use:   "use",
+		Short: "Selects the Jira profile used when no profile matches the repository",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+			if !config.HasProfile(name) {
+				logCmdFatal(fmt.Errorf("profile %q does not exist, available: %s", name, strings.Join(config.Profiles(), ",")))
+			}
+
+			if err := config.SetString(config.ProjectProfile, name); err != nil {
+				logCmdFatal(err)
+			}
+
+			log.Info().Println(fmt.Sprintf("Using profile %q", name))
+		},
+	}
+	configUnsetCmd = &cobra.Command{
+		Use:   "unset",
+		Short: "Removes the key from the config file, so the value of a lower layer is used again",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			token, err := config.FromInput(args[0])
+			if err != nil {
+				logCmdFatal(err)
+			}
+
+			useTargetLayer()
+
+			if err = config.Unset(token); err != nil {
+				logCmdFatal(err)
+			}
+
+			log.Info().Println(fmt.Sprintf("Unset %q, using value from %s config", args[0], config.Source(token)))
+		},
+	}
+	configEditCmd = &cobra.Command{
+		Use:   "edit",
+		Short: "Opens the config file in $VISUAL or $EDITOR and validates it after saving",
+		Args:  cobra.NoArgs,
+		Run:   runConfigEdit,
+	}
+	configExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Prints the merged config (or only the given sections) without secrets, to share it with a team",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			sections := make([]config.Token, len(args))
+			for i, arg := range args {
+				token, err := config.FromInput(arg)
+				if err != nil || config.Lookup(token).Kind != config.KindSection {
+					logCmdFatal(fmt.Errorf("%q is not a config section", arg))
+				}
+				sections[i] = token
+			}
+
+			if err := config.Export(os.Stdout, sections...); err != nil {
+				logCmdFatal(err)
+			}
+		},
+	}
+	configImportCmd = &cobra.Command{
+		Use:   "import",
+		Short: "Merges a shared config file into the config, secrets and unknown keys are skipped",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			useTargetLayer()
+
+			if _, err := config.TargetPath(); err != nil {
+				logCmdFatal(err)
+			}
+
+			keys, err := config.Import(args[0])
+			if err != nil {
+				logCmdFatal(err)
+			}
+
+			log.Info().Println(fmt.Sprintf("Imported %d key(s) from %q", len(keys), args[0]))
+		},
+	}
+	configMigrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrades config files written by older twig versions, keeping a backup of every file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			migrations, err := config.Migrate(migrateDryRun)
+			if err != nil {
+				logCmdFatal(err)
+			}
+
+			if len(migrations) == 0 {
+				log.Info().Println(fmt.Sprintf("Config is up to date (version %d)", config.CurrentVersion))
+				return
+			}
+
+			for _, m := range migrations {
+				log.Info().Println(fmt.Sprintf("%s: version %d -> %d", m.Layer, m.From, m.To))
+				for _, change := range m.Changes {
+					log.Info().Println(fmt.Sprintf("    %s", change))
+				}
+			}
+
+			if migrateDryRun {
+				log.Warn().Println("Dry run, nothing is written")
+			}
 		},
 	}
 	configGetCmd = &cobra.Command{
@@ -57,10 +182,14 @@ var (
 				logCmdFatal(err)
 			}
 
-			if strings.Contains(input, config.FromToken(config.Mapping)) || input == config.FromToken(config.BranchExclude) {
-				printStringArr(token, config.GetStringArray(token))
-			} else {
-				printString(token, config.GetString(token))
+			key := config.Lookup(token)
+			if key.Kind != config.KindSection {
+				printKey(key)
+				return
+			}
+
+			for _, key := range config.KeysOf(token) {
+				printKey(key)
 			}
 		},
 	}
@@ -76,30 +205,209 @@ var (
 				logCmdFatal(err)
 			}
 
-			if strings.Contains(name, config.FromToken(config.Mapping)) || name == config.FromToken(config.BranchExclude) {
-				if err := config.SetStringArray(token, strings.Split(value, ",")); err != nil {
-					logCmdFatal(err)
-				}
-			} else {
-				if err := config.SetString(token, value); err != nil {
-					logCmdFatal(err)
-				}
+			useTargetLayer()
+
+			if err := config.Set(token, value); err != nil {
+				logCmdFatal(err)
 			}
 		},
 	}
 )
 
 func init() {
+	configListCmd.Flags().BoolVar(
+		&showSources,
+		"show-sources",
+		false,
+		"(optional) show the config layer each value comes from and the environment variable overriding it",
+	)
+
+	configSetCmd.Flags().BoolVar(
+		&setLocal,
+		"local",
+		false,
+		"(optional) write into .twig.toml of the current repository instead of the global config",
+	)
+
+	for _, cmd := range []*cobra.Command{configUnsetCmd, configEditCmd, configImportCmd} {
+		cmd.Flags().BoolVar(
+			&setLocal,
+			"local",
+			false,
+			"(optional) use .twig.toml of the current repository instead of the global config",
+		)
+	}
+
+	configMigrateCmd.Flags().BoolVar(
+		&migrateDryRun,
+		"dry-run",
+		false,
+		"(optional) only print the changes",
+	)
+
+	configCmd.Long = describeKeys()
+
 	configCmd.AddCommand(
 		configListCmd,
 		configGetCmd,
 		configSetCmd,
+		configUnsetCmd,
+		configEditCmd,
+		configExportCmd,
+		configImportCmd,
+		configMigrateCmd,
+		configUseCmd,
+		configValidateCmd,
 	)
 }
 
+// useTargetLayer makes config changes go into .twig.toml when --local is set.
+func useTargetLayer() {
+	if !setLocal {
+		return
+	}
+
+	if err := config.UseRepoLayer(); err != nil {
+		logCmdFatal(err)
+	}
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) {
+	useTargetLayer()
+
+	path, err := config.TargetPath()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	var input *prompt.PosixParser
+
+	for {
+		if err = openEditor(path); err != nil {
+			logCmdFatal(err)
+		}
+
+		problems, err := validateFile(path)
+		if err == nil && len(problems) == 0 {
+			log.Info().Println(fmt.Sprintf("Saved %q, config is valid", path))
+			return
+		}
+
+		if err != nil {
+			log.Error().Println(err.Error())
+		} else {
+			printProblems(problems)
+		}
+
+		if input == nil {
+			input = prompt.NewStandardInputParser()
+		}
+
+		fmt.Print(color.New(color.FgHiGreen).Sprint("Edit again? (y/n): "))
+		str, err := input.Read()
+		if err != nil {
+			logCmdFatal(err)
+		}
+
+		if strings.ToLower(strings.TrimSpace(string(str))) != "y" {
+			logCmdFatal(fmt.Errorf("%q is saved with problems", path))
+		}
+	}
+}
+
+// validateFile reloads the layers and returns the problems of the values from the file.
+func validateFile(path string) ([]config.Problem, error) {
+	if err := config.Reload(); err != nil {
+		return nil, err
+	}
+
+	problems := make([]config.Problem, 0)
+	for _, problem := range config.Validate() {
+		if config.SourceOf(problem.Key).Path == path {
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems, nil
+}
+
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := append(strings.Fields(editor), path)
+	command := exec.Command(args[0], args[1:]...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("editor %q: %w", editor, err)
+	}
+
+	return nil
+}
+
+// printKey prints the current value of the key, secrets are masked.
+func printKey(key config.Key) {
+	if key.Kind == config.KindArray {
+		printStringArr(key.Token, config.GetStringArray(key.Token))
+		return
+	}
+
+	value := config.GetString(key.Token)
+	if key.Secret {
+		value = secret.Mask(value)
+	}
+
+	printString(key.Token, value)
+}
+
+// describeKeys lists every key along with its description for the help of twig config.
+func describeKeys() string {
+	var sb strings.Builder
+	sb.WriteString(configCmd.Short)
+	sb.WriteString("\n\nKeys:\n")
+
+	for _, key := range config.Keys() {
+		sb.WriteString(fmt.Sprintf("  %-18s %s\n", key.Name, key.Description))
+	}
+
+	return sb.String()
+}
+
 func printString(token config.Token, value string) {
-	log.Info().Print(fmt.Sprintf("%s=%s", config.FromToken(token), value))
+	log.Info().Print(formatConfigLine(token, value))
 }
+
 func printStringArr(token config.Token, values []string) {
-	log.Info().Print(fmt.Sprintf("%s=%s", config.FromToken(token), strings.Join(values, ",")))
+	log.Info().Print(formatConfigLine(token, strings.Join(values, ",")))
+}
+
+func printProfile(name string, profile config.ProfileSettings) {
+	prefix := fmt.Sprintf("profiles.%s", name)
+	if name == config.ActiveProfile() {
+		log.Info().Print(fmt.Sprintf("# %s is active", prefix))
+	}
+
+	log.Info().Print(fmt.Sprintf("%s.host=%s", prefix, profile.Host))
+	log.Info().Print(fmt.Sprintf("%s.auth=%s", prefix, profile.Auth))
+	log.Info().Print(fmt.Sprintf("%s.email=%s", prefix, profile.Email))
+	log.Info().Print(fmt.Sprintf("%s.token=%s", prefix, secret.Mask(profile.Token)))
+	log.Info().Print(fmt.Sprintf("%s.remotes=%s", prefix, strings.Join(profile.Remotes, ",")))
+	log.Info().Print(fmt.Sprintf("%s.paths=%s", prefix, strings.Join(profile.Paths, ",")))
+}
+
+func formatConfigLine(token config.Token, value string) string {
+	line := fmt.Sprintf("%s=%s", config.FromToken(token), value)
+	if !showSources {
+		return line
+	}
+
+	return fmt.Sprintf("%s\t%s\t$%s", config.Source(token), line, config.EnvName(token))
 }
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
-	"net/http"
 	"twig/branch"
 	"twig/common"
 	"twig/config"
@@ -17,10 +16,11 @@ var (
 	shouldPush    bool
 	createCmdName = "create"
 	createCmd     = &cobra.Command{
-		Use:   createCmdName,
-		Short: "Create branch from Jira Issue",
-		Args:  cobra.MinimumNArgs(1),
-		Run:   runCreate,
+		Use:         createCmdName,
+		Short:       "Create branch from Jira Issue",
+		Args:        cobra.MinimumNArgs(1),
+		Run:         runCreate,
+		Annotations: map[string]string{annotationRequiresGit: "true"},
 	}
 )
 
@@ -44,9 +44,7 @@ func init() {
 func runCreate(cmd *cobra.Command, args []string) {
 	log.Debug().Println("create: executing command")
 
-	httpClient := &http.Client{}
-	client := network.NewHttpClient(httpClient)
-	api:= network.NewJiraApi(client)
+	api := newJiraApi()
 	issue := args[0]
 
 	if err := validateIssue(issue); err != nil {
@@ -88,25 +86,38 @@ func runCreate(cmd *cobra.Command, args []string) {
 		b.Type = bt
 	}
 
+	repo, err := openRepository()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	remote := config.GetString(config.BranchOrigin)
 	branchName := b.BuildName(*jiraIssue)
-	hasBranch := common.HasBranch(branchName)
 
-	checkoutCommand, err := common.Checkout(branchName, hasBranch)
+	// a branch pushed by a teammate is tracked instead of creating a divergent one
+	location, err := common.FindBranch(repo, remote, branchName)
 	if err != nil {
 		logCmdFatal(err)
 	}
 
-	log.Info().Println(checkoutCommand)
+	checkoutCommand, err := common.Checkout(repo, remote, branchName, location)
+	if err != nil {
+		logCmdFatal(err)
+	}
 
-	if shouldPush {
-		remote := config.GetString(config.BranchOrigin)
+	if checkoutCommand != "" {
+		log.Info().Println(checkoutCommand)
+	}
 
-		pushCommand, err := common.PushToRemote(branchName, remote)
+	if shouldPush {
+		pushCommand, err := common.PushToRemote(repo, branchName, remote)
 		if err != nil {
 			logCmdFatal(err)
 		}
 
-		log.Info().Println(pushCommand)
+		if pushCommand != "" {
+			log.Info().Println(pushCommand)
+		}
 	}
 }
 
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"twig/config"
+	"twig/git"
+	"twig/git/gittest"
+	"twig/log"
+	"twig/network"
+)
+
+func init() {
+	log.CreateNoOpTestRecorders()
+}
+
+const testConfig = `
+[project]
+email = "john.doe@example.com"
+
+[branch]
+default = "development"
+origin = "origin"
+exclude = ["be"]
+
+[mapping]
+feat = ["10001"]
+fix = ["10002"]
+`
+
+type fakeJiraApi struct {
+	issues map[string]network.JiraIssue
+	types  []network.IssueType
+}
+
+func newFakeJiraApi(issues ...network.JiraIssue) *fakeJiraApi {
+	api := &fakeJiraApi{
+		issues: make(map[string]network.JiraIssue),
+		types:  []network.IssueType{{Id: "10001", Name: "Story"}, {Id: "10002", Name: "Bug"}},
+	}
+
+	for _, jiraIssue := range issues {
+		api.issues[jiraIssue.Key] = jiraIssue
+	}
+
+	return api
+}
+
+func (api *fakeJiraApi) GetCurrentUser() (*network.JiraUser, error) {
+	return &network.JiraUser{Email: "john.doe@example.com"}, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssueTypes() ([]network.IssueType, error) {
+	return api.types, nil
+}
+
+func (api *fakeJiraApi) GetJiraProjectIssueTypes(projectKey string) ([]network.IssueType, error) {
+	return api.types, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssue(issueKey string) (*network.JiraIssue, error) {
+	jiraIssue, ok := api.issues[issueKey]
+	if !ok {
+		return nil, &network.ResponseError{StatusCode: 404, Message: "issue does not exist"}
+	}
+
+	return &jiraIssue, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssueStatus(issueKey string, hasAssignee bool) (*network.JiraIssue, error) {
+	return api.GetJiraIssue(issueKey)
+}
+
+func (api *fakeJiraApi) GetJiraIssueStatusBulk(issueKeys []string, hasAssignee bool) ([]network.JiraIssue, error) {
+	issues := make([]network.JiraIssue, 0, len(issueKeys))
+	for _, key := range issueKeys {
+		if jiraIssue, ok := api.issues[key]; ok {
+			issues = append(issues, jiraIssue)
+		}
+	}
+
+	return issues, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssueDetails(issueKey, sprintField string) (*network.JiraIssue, error) {
+	return api.GetJiraIssue(issueKey)
+}
+
+func (api *fakeJiraApi) GetJiraIssueDetailsBulk(issueKeys []string, sprintField string) ([]network.JiraIssue, error) {
+	return api.GetJiraIssueStatusBulk(issueKeys, true)
+}
+
+func (api *fakeJiraApi) GetJiraIssueDescription(issueKey string) (*network.JiraIssue, error) {
+	return api.GetJiraIssue(issueKey)
+}
+
+func newJiraIssue(key, typeId, summary string) network.JiraIssue {
+	return network.JiraIssue{
+		Key: key,
+		Fields: network.IssueFields{
+			Type:    &network.IssueType{Id: typeId},
+			Summary: &summary,
+		},
+	}
+}
+
+// useTestConfig loads the toml as the only config file.
+func useTestConfig(t *testing.T, toml string) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "twig.toml")
+	if err := os.WriteFile(path, []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config.InitConfig(path, "")
+}
+
+// useFakes makes commands run against the repository and the Jira api.
+func useFakes(t *testing.T, repo git.Repository, api network.JiraApi) {
+	open, newApi := openRepository, newJiraApi
+	t.Cleanup(func() {
+		openRepository, newJiraApi = open, newApi
+	})
+
+	openRepository = func() (git.Repository, error) {
+		return repo, nil
+	}
+	newJiraApi = func() network.JiraApi {
+		return api
+	}
+}
+
+func useCreateFlags(t *testing.T, bt string, push bool) {
+	t.Cleanup(func() {
+		branchType, shouldPush = "", false
+	})
+
+	branchType, shouldPush = bt, push
+}
+
+func TestRunCreateNewBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development")
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-123", "10001", "[BE] Add login page")))
+	useCreateFlags(t, "", false)
+
+	runCreate(createCmd, []string{"ABC-123"})
+
+	want := []string{"checkout -b feat/ABC-123_add-login-page"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runCreate(ABC-123) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestRunCreateExistingBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development", "fix/ABC-7_broken-logout")
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-7", "10002", "Broken logout")))
+	useCreateFlags(t, "", false)
+
+	runCreate(createCmd, []string{"ABC-7"})
+
+	want := []string{"checkout fix/ABC-7_broken-logout"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runCreate(ABC-7) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestRunCreateTypeAndPush(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development")
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-9", "10001", "Slow search")))
+	useCreateFlags(t, "perf", true)
+
+	runCreate(createCmd, []string{"ABC-9"})
+
+	want := []string{"checkout -b perf/ABC-9_slow-search", "push -u origin perf/ABC-9_slow-search"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runCreate(ABC-9) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestRunCreatePushError(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development")
+	repo.Errors["Push"] = errors.New("remote rejected")
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-9", "10001", "Slow search")))
+	useCreateFlags(t, "", true)
+
+	runCreate(createCmd, []string{"ABC-9"})
+
+	if !repo.HasBranch("feat/ABC-9_slow-search") || len(repo.Remote["origin"]) != 0 {
+		t.Errorf(`runCreate(ABC-9) = %q, want only a local branch`, repo.Commands)
+	}
+}
+
+func TestRunCreateRemoteTrackingBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development")
+	repo.Remote["origin"] = []string{"fix/ABC-7_broken-logout"}
+	repo.Tracking["origin"] = []string{"fix/ABC-7_broken-logout"}
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-7", "10002", "Broken logout")))
+	useCreateFlags(t, "", false)
+
+	runCreate(createCmd, []string{"ABC-7"})
+
+	want := []string{"checkout -b fix/ABC-7_broken-logout --track origin/fix/ABC-7_broken-logout"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runCreate(ABC-7) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestRunCreateRemoteOnlyBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development")
+	repo.Remote["origin"] = []string{"fix/ABC-7_broken-logout"}
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-7", "10002", "Broken logout")))
+	useCreateFlags(t, "", false)
+
+	runCreate(createCmd, []string{"ABC-7"})
+
+	want := []string{"fetch origin", "checkout -b fix/ABC-7_broken-logout --track origin/fix/ABC-7_broken-logout"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runCreate(ABC-7) = %q, want match for %q`, repo.Commands, want)
+	}
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"twig/common"
+	"twig/config"
+	"twig/log"
+	"twig/network"
+	"twig/secret"
+)
+
+var (
+	doctorCmdName = "doctor"
+	doctorCmd     = &cobra.Command{
+		Use:   doctorCmdName,
+		Short: "Checks git, config, Jira connectivity, auth and issue type mapping",
+		Args:  cobra.NoArgs,
+		Run:   runDoctor,
+	}
+)
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	log.Debug().Println("doctor: executing command")
+
+	failed := 0
+
+	if !common.HasGit() {
+		failed += printCheck("git", []config.Problem{{
+			Key:     "git",
+			Message: "git is not installed or not in PATH",
+			Fix:     "install git from https://git-scm.com/downloads",
+		}})
+	} else {
+		failed += printCheck("git", nil)
+	}
+
+	failed += printCheck("config", config.Validate())
+
+	failed += printCheck("token", checkToken())
+
+	api := newJiraApi()
+
+	user, problems := checkJiraAuth(api)
+	failed += printCheck("jira", problems)
+
+	if user == nil {
+		log.Warn().Println("[skip] mapping, Jira is not reachable")
+	} else {
+		log.Info().Println(fmt.Sprintf("       authenticated as %s <%s>", user.DisplayName, user.Email))
+		failed += printCheck("mapping", checkMapping(api))
+	}
+
+	if failed > 0 {
+		logCmdFatal(fmt.Errorf("%d check(s) failed", failed))
+	}
+}
+
+func checkToken() []config.Problem {
+	token := config.GetString(config.ProjectToken)
+	account := secret.Account{
+		Host: config.GetString(config.ProjectHost),
+		User: config.GetString(config.ProjectEmail),
+	}
+
+	if _, err := secret.Resolve(token, account); err != nil {
+		return []config.Problem{{
+			Key:     config.FromToken(config.ProjectToken),
+			Message: err.Error(),
+			Fix:     fmt.Sprintf("check that %q is stored in its backend or run twig init", token),
+		}}
+	}
+
+	return nil
+}
+
+func checkJiraAuth(api network.JiraApi) (*network.JiraUser, []config.Problem) {
+	user, err := api.GetCurrentUser()
+	if err == nil {
+		return user, nil
+	}
+
+	problem := config.Problem{
+		Key:     config.FromToken(config.ProjectHost),
+		Message: err.Error(),
+		Fix:     "check the network connection and the host",
+	}
+
+	var urlErr *url.Error
+	var responseErr *network.ResponseError
+
+	switch {
+	case errors.As(err, &urlErr):
+		problem.Fix = fmt.Sprintf("check that %q is reachable and correct", config.GetString(config.ProjectHost))
+	case errors.As(err, &responseErr) && (responseErr.StatusCode == http.StatusUnauthorized || responseErr.StatusCode == http.StatusForbidden):
+		problem.Key = config.FromToken(config.ProjectToken)
+		problem.Fix = fmt.Sprintf(
+			"check %s, %s and %s (basic for Jira Cloud API tokens, bearer for Data Center PATs)",
+			config.FromToken(config.ProjectEmail),
+			config.FromToken(config.ProjectToken),
+			config.FromToken(config.ProjectAuth),
+		)
+	case errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound:
+		problem.Fix = "check that the host points to Jira, twig uses /rest/api/2"
+	}
+
+	return nil, []config.Problem{problem}
+}
+
+func checkMapping(api network.JiraApi) []config.Problem {
+	issueTypes, err := api.GetJiraIssueTypes()
+	if err != nil {
+		return []config.Problem{{
+			Key:     config.FromToken(config.Mapping),
+			Message: err.Error(),
+			Fix:     "check that the user may browse issue types",
+		}}
+	}
+
+	known := make(map[string]network.IssueType)
+	for _, issueType := range issueTypes {
+		known[issueType.Id] = issueType
+	}
+
+	problems := make([]config.Problem, 0)
+	mapped := make(map[string]bool)
+	mapping := config.GetStringMap(config.Mapping)
+
+	for _, name := range slices.Sorted(maps.Keys(mapping)) {
+		for _, id := range mapping[name] {
+			if id == "0" {
+				continue
+			}
+
+			mapped[id] = true
+
+			if _, ok := known[id]; !ok {
+				key := fmt.Sprintf("%s.%s", config.FromToken(config.Mapping), name)
+				problems = append(problems, config.Problem{
+					Key:     key,
+					Message: fmt.Sprintf("issue type %q does not exist in Jira", id),
+					Fix:     fmt.Sprintf("twig config set %s <ids>, see the issue types below", key),
+				})
+			}
+		}
+	}
+
+	unmapped := make([]string, 0)
+	for _, issueType := range issueTypes {
+		if !mapped[issueType.Id] {
+			unmapped = append(unmapped, fmt.Sprintf("%s (%s)", issueType.Name, issueType.Id))
+		}
+	}
+
+	if len(unmapped) > 0 {
+		log.Warn().Println(fmt.Sprintf("       not mapped issue types: %s", strings.Join(unmapped, ", ")))
+	}
+
+	return problems
+}
+
+// printCheck prints the check result and returns 1 if it failed.
+func printCheck(name string, problems []config.Problem) int {
+	if len(problems) == 0 {
+		log.Info().Println(fmt.Sprintf("[ok]   %s", name))
+		return 0
+	}
+
+	log.Error().Println(fmt.Sprintf("[fail] %s", name))
+	printProblems(problems)
+
+	return 1
+}
+
+func printProblems(problems []config.Problem) {
+	for _, problem := range problems {
+		log.Error().Println(fmt.Sprintf("       %s", problem))
+		log.Warn().Println(fmt.Sprintf("       fix: %s", problem.Fix))
+	}
+}
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"twig/branch"
+	"twig/commit"
+	"twig/config"
+	"twig/git"
+	"twig/log"
+)
+
+const (
+	hookPrepareCommitMsg = "prepare-commit-msg"
+	hookCommitMsg        = "commit-msg"
+	hookMarker           = "# installed by twig"
+	hookBackupSuffix     = ".bak"
+)
+
+var (
+	hookConfigErr      error // config of the hook runners failed to load, they leave the message unchanged
+	hookValidate       bool
+	hookForce          bool
+	hookCmdName        = "hook"
+	hookInstallCmdName = "install"
+	hookCmd            = &cobra.Command{
+		Use:   hookCmdName,
+		Short: "Manages git hooks which format commit messages after commit.template",
+		Args:  cobra.NoArgs,
+	}
+	hookInstallCmd = &cobra.Command{
+		Use:   hookInstallCmdName,
+		Short: "Installs the prepare-commit-msg and commit-msg hooks, the latter optionally validates messages",
+		Args:  cobra.NoArgs,
+		Run:   runHookInstall,
+	}
+	hookUninstallCmd = &cobra.Command{
+		Use:   "uninstall",
+		Short: "Removes the hooks installed by twig and restores the replaced ones",
+		Args:  cobra.NoArgs,
+		Run:   runHookUninstall,
+	}
+	// run by the installed hooks with the arguments git passes them
+	hookPrepareCommitMsgCmd = &cobra.Command{
+		Use:    hookPrepareCommitMsg,
+		Hidden: true,
+		Args:   cobra.RangeArgs(1, 3),
+		Run:    runPrepareCommitMsg,
+	}
+	hookCommitMsgCmd = &cobra.Command{
+		Use:    hookCommitMsg,
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		Run:    runCommitMsg,
+	}
+)
+
+func init() {
+	hookInstallCmd.Flags().BoolVar(
+		&hookValidate,
+		"validate",
+		false,
+		"(optional) let the commit-msg hook reject messages not matching the template",
+	)
+
+	hookCommitMsgCmd.Flags().BoolVar(
+		&hookValidate,
+		"validate",
+		false,
+		"(optional) reject messages not matching the template",
+	)
+
+	hookInstallCmd.Flags().BoolVarP(
+		&hookForce,
+		"force",
+		"f",
+		false,
+		fmt.Sprintf("(optional) replace hooks not installed by twig, they are kept with the %s suffix", hookBackupSuffix),
+	)
+
+	hookCmd.AddCommand(
+		hookInstallCmd,
+		hookUninstallCmd,
+		hookPrepareCommitMsgCmd,
+		hookCommitMsgCmd,
+	)
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) {
+	log.Debug().Println("hook: executing install command")
+
+	dir, err := hooksDir()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		log.Debug().Println(fmt.Sprintf("Executable: %s, hooks run twig from PATH", err.Error()))
+		executable = "twig"
+	}
+
+	for _, name := range []string{hookPrepareCommitMsg, hookCommitMsg} {
+		var args []string
+		if name == hookCommitMsg && hookValidate {
+			args = append(args, "--validate")
+		}
+
+		path, err := installHook(dir, name, executable, args...)
+		if err != nil {
+			logCmdFatal(err)
+		}
+
+		log.Info().Println(fmt.Sprintf("Installed %q", path))
+	}
+}
+
+func runHookUninstall(cmd *cobra.Command, args []string) {
+	log.Debug().Println("hook: executing uninstall command")
+
+	dir, err := hooksDir()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	for _, name := range []string{hookPrepareCommitMsg, hookCommitMsg} {
+		removed, err := uninstallHook(dir, name)
+		if err != nil {
+			logCmdFatal(err)
+		}
+
+		if removed {
+			log.Info().Println(fmt.Sprintf("Removed %q", filepath.Join(dir, name)))
+		}
+	}
+}
+
+func hooksDir() (string, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return "", err
+	}
+
+	return repo.HooksDir()
+}
+
+// hookScript calls twig back, so updating twig updates the hooks too. The arguments follow the ones of git.
+func hookScript(name, executable string, args ...string) []byte {
+	quoted := "'" + strings.ReplaceAll(executable, "'", `'\''`) + "'"
+	command := strings.Join(append([]string{quoted, hookCmdName, name, `"$@"`}, args...), " ")
+
+	return []byte(fmt.Sprintf("#!/bin/sh\n%s, remove with: twig hook uninstall\nexec %s\n", hookMarker, command))
+}
+
+func isTwigHook(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Contains(content, []byte(hookMarker)), nil
+}
+
+// installHook writes the hook, a hook of another tool is only replaced with --force and kept as a backup.
+func installHook(dir, name, executable string, args ...string) (string, error) {
+	path := filepath.Join(dir, name)
+
+	ours, err := isTwigHook(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("hook: %w", err)
+	}
+
+	if err == nil && !ours {
+		if !hookForce {
+			return "", fmt.Errorf("hook: %q exists, use --force to replace it", path)
+		}
+
+		if err := os.Rename(path, path+hookBackupSuffix); err != nil {
+			return "", fmt.Errorf("hook: %w", err)
+		}
+
+		log.Warn().Println(fmt.Sprintf("Kept the replaced hook as %q", path+hookBackupSuffix))
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("hook: %w", err)
+	}
+
+	if err := os.WriteFile(path, hookScript(name, executable, args...), 0o755); err != nil {
+		return "", fmt.Errorf("hook: %w", err)
+	}
+
+	return path, nil
+}
+
+// uninstallHook removes the hook if twig installed it and restores the hook it replaced.
+func uninstallHook(dir, name string) (bool, error) {
+	path := filepath.Join(dir, name)
+
+	ours, err := isTwigHook(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("hook: %w", err)
+	}
+
+	if !ours {
+		log.Warn().Println(fmt.Sprintf("Skip %q, it was not installed by twig", path))
+		return false, nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return false, fmt.Errorf("hook: %w", err)
+	}
+
+	if _, err := os.Stat(path + hookBackupSuffix); err == nil {
+		if err := os.Rename(path+hookBackupSuffix, path); err != nil {
+			return true, fmt.Errorf("hook: %w", err)
+		}
+
+		log.Info().Println(fmt.Sprintf("Restored %q", path))
+	}
+
+	return true, nil
+}
+
+// runPrepareCommitMsg formats the message given with -m or -F, the message written in the editor is formatted
+// by runCommitMsg. It never fails the commit, branches without an issue key or type and messages which fail
+// to load the config are committed as they are.
+func runPrepareCommitMsg(cmd *cobra.Command, args []string) {
+	// messages of merges, squashes and reused commits (-c, -C, --amend) are kept
+	if len(args) > 1 && slices.Contains([]string{"merge", "squash", "commit"}, args[1]) {
+		return
+	}
+
+	branchType, key, template, err := commitContext()
+	if err != nil {
+		log.Debug().Println(fmt.Sprintf("hook: %s", err.Error()))
+		return
+	}
+
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		log.Warn().Println(fmt.Sprintf("hook: %s", err.Error()))
+		return
+	}
+
+	message := template.Apply(branchType, key, string(content))
+	if err := os.WriteFile(args[0], []byte(message), 0o644); err != nil {
+		log.Warn().Println(fmt.Sprintf("hook: %s", err.Error()))
+	}
+}
+
+// runCommitMsg formats the message written in the editor, which is still empty when prepare-commit-msg runs.
+// With --validate it rejects a message not matching the template, which makes git abort the commit.
+// Without a config it can't tell the template and accepts the message.
+func runCommitMsg(cmd *cobra.Command, args []string) {
+	branchType, key, template, err := commitContext()
+	if err != nil {
+		log.Debug().Println(fmt.Sprintf("hook: %s", err.Error()))
+		return
+	}
+
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	message := template.Apply(branchType, key, string(content))
+	if message != string(content) {
+		if err := os.WriteFile(args[0], []byte(message), 0o644); err != nil {
+			logCmdFatal(err)
+		}
+	}
+
+	if !hookValidate {
+		return
+	}
+
+	subject := commit.Subject(message)
+	if commit.IsExempt(subject) || template.Matches(branchType, key, subject) {
+		return
+	}
+
+	logCmdFatal(fmt.Errorf(
+		"commit message %q must look like %q",
+		subject,
+		template.Format(branchType, key, "<message>"),
+	))
+}
+
+// commitContext returns the type and issue key of the current branch along with commit.template.
+func commitContext() (string, string, *commit.Template, error) {
+	if hookConfigErr != nil {
+		return "", "", nil, hookConfigErr
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return branchCommitContext(repo)
+}
+
+func branchCommitContext(repo git.Repository) (string, string, *commit.Template, error) {
+	template, err := commit.NewTemplate(config.GetString(config.CommitTemplate))
+	if err != nil {
+		return "", "", nil, fmt.Errorf("config: %q %w", config.FromToken(config.CommitTemplate), err)
+	}
+
+	name, err := repo.CurrentBranch()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	b, err := newIssueKeyBranch()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	key, err := b.ExtractIssueNameFromBranch(name)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("branch %q: %w", name, err)
+	}
+
+	bt, err := branch.TypeFromBranch(name)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// subjects already formatted for another type or issue are kept
+	template.Recognize(branch.Types(), b.KeyRegexp())
+
+	return bt.ToString(), key, template, nil
+}
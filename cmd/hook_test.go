@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"twig/git/gittest"
+)
+
+func useHookFlags(t *testing.T, validate, force bool) {
+	t.Cleanup(func() {
+		hookValidate, hookForce = false, false
+	})
+
+	hookValidate, hookForce = validate, force
+}
+
+func writeCommitMessage(t *testing.T, message string) string {
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(path, []byte(message), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content)
+}
+
+func TestRunHookInstallAndUninstall(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useHookFlags(t, true, true)
+
+	repo := gittest.New("development")
+	repo.Hooks = t.TempDir()
+	useFakes(t, repo, newFakeJiraApi())
+
+	foreign := filepath.Join(repo.Hooks, hookCommitMsg)
+	if err := os.WriteFile(foreign, []byte("#!/bin/sh\nlint\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	runHookInstall(hookInstallCmd, nil)
+
+	for _, name := range []string{hookPrepareCommitMsg, hookCommitMsg} {
+		script := readFile(t, filepath.Join(repo.Hooks, name))
+		if !strings.Contains(script, hookMarker) || !strings.Contains(script, "hook "+name+` "$@"`) {
+			t.Errorf(`runHookInstall() = %q, want twig hook %s`, script, name)
+		}
+	}
+
+	if script := readFile(t, filepath.Join(repo.Hooks, hookCommitMsg)); !strings.Contains(script, `"$@" --validate`) {
+		t.Errorf(`runHookInstall(--validate) = %q, want the commit-msg hook to validate`, script)
+	}
+
+	if backup := readFile(t, foreign+hookBackupSuffix); backup != "#!/bin/sh\nlint\n" {
+		t.Errorf(`runHookInstall() = %q, want the replaced hook kept`, backup)
+	}
+
+	runHookUninstall(hookUninstallCmd, nil)
+
+	if _, err := os.Stat(filepath.Join(repo.Hooks, hookPrepareCommitMsg)); !os.IsNotExist(err) {
+		t.Errorf(`runHookUninstall() = %v, want %s removed`, err, hookPrepareCommitMsg)
+	}
+
+	if restored := readFile(t, foreign); restored != "#!/bin/sh\nlint\n" {
+		t.Errorf(`runHookUninstall() = %q, want the replaced hook restored`, restored)
+	}
+}
+
+func TestInstallHookKeepsForeignHook(t *testing.T) {
+	useHookFlags(t, false, false)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, hookPrepareCommitMsg)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := installHook(dir, hookPrepareCommitMsg, "/usr/local/bin/twig"); err == nil {
+		t.Errorf(`installHook() = nil, want error without --force`)
+	}
+}
+
+func TestRunPrepareCommitMsg(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useFakes(t, gittest.New("fix/ABC-123_handle-null-token"), newFakeJiraApi())
+
+	path := writeCommitMessage(t, "handle null token\n")
+	runPrepareCommitMsg(hookPrepareCommitMsgCmd, []string{path, "message"})
+
+	want := "fix(ABC-123): handle null token\n"
+	if subject := readFile(t, path); subject != want {
+		t.Errorf(`runPrepareCommitMsg() = %q, want match for %q`, subject, want)
+	}
+
+	path = writeCommitMessage(t, "Merge branch 'development'\n")
+	runPrepareCommitMsg(hookPrepareCommitMsgCmd, []string{path, "merge"})
+
+	want = "Merge branch 'development'\n"
+	if subject := readFile(t, path); subject != want {
+		t.Errorf(`runPrepareCommitMsg(merge) = %q, want match for %q`, subject, want)
+	}
+}
+
+func TestRunPrepareCommitMsgKeepsEmptyMessage(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useFakes(t, gittest.New("fix/ABC-123_handle-null-token"), newFakeJiraApi())
+
+	// git aborts the commit of an empty message only if it stays empty
+	want := "\n# Please enter the commit message for your changes.\n"
+	path := writeCommitMessage(t, want)
+	runPrepareCommitMsg(hookPrepareCommitMsgCmd, []string{path})
+
+	if subject := readFile(t, path); subject != want {
+		t.Errorf(`runPrepareCommitMsg() = %q, want match for %q`, subject, want)
+	}
+}
+
+func TestRunCommitMsgFormatsEditorMessage(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useFakes(t, gittest.New("fix/ABC-123_handle-null-token"), newFakeJiraApi())
+
+	// without a source git opens the editor after prepare-commit-msg, the message holds only comments then
+	path := writeCommitMessage(t, "\n# Please enter the commit message for your changes.\n")
+	runPrepareCommitMsg(hookPrepareCommitMsgCmd, []string{path})
+
+	if err := os.WriteFile(path, []byte("handle null token\n\ncheck the token first\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runCommitMsg(hookCommitMsgCmd, []string{path})
+
+	want := "fix(ABC-123): handle null token\n\ncheck the token first\n"
+	if subject := readFile(t, path); subject != want {
+		t.Errorf(`runCommitMsg() = %q, want match for %q`, subject, want)
+	}
+}
+
+func TestRunPrepareCommitMsgWithoutConfig(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useFakes(t, gittest.New("fix/ABC-123_handle-null-token"), newFakeJiraApi())
+
+	t.Cleanup(func() {
+		hookConfigErr = nil
+	})
+	hookConfigErr = errors.New(`profile "acme" does not exist`)
+
+	path := writeCommitMessage(t, "handle null token\n")
+	runPrepareCommitMsg(hookPrepareCommitMsgCmd, []string{path, "message"})
+	runCommitMsg(hookCommitMsgCmd, []string{path})
+
+	if subject := readFile(t, path); subject != "handle null token\n" {
+		t.Errorf(`runPrepareCommitMsg() = %q, want the message unchanged`, subject)
+	}
+}
+
+func TestRunPrepareCommitMsgWithoutIssue(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useFakes(t, gittest.New("development"), newFakeJiraApi())
+
+	path := writeCommitMessage(t, "bump version\n")
+	runPrepareCommitMsg(hookPrepareCommitMsgCmd, []string{path})
+
+	want := "bump version\n"
+	if subject := readFile(t, path); subject != want {
+		t.Errorf(`runPrepareCommitMsg() = %q, want match for %q`, subject, want)
+	}
+}
+
+func TestBranchCommitContextTemplate(t *testing.T) {
+	useTestConfig(t, testConfig+"\n[commit]\ntemplate = \"[{key}] {message}\"\n")
+
+	branchType, key, template, err := branchCommitContext(gittest.New("feat/ABC-7_login"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[ABC-7] add login"
+	if subject := template.Format(branchType, key, "add login"); subject != want || branchType != "feat" {
+		t.Errorf(`branchCommitContext() = %q %q, want match for %q`, branchType, subject, want)
+	}
+}
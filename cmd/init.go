@@ -1,20 +1,41 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"github.com/c-bata/go-prompt"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"strconv"
+	"net/http"
+	"path"
 	"strings"
+	"time"
 	"twig/config"
 	"twig/log"
 	"twig/network"
+	"twig/secret"
 )
 
+const (
+	initModeForce = "force"
+	initModeMerge = "merge"
+	initModeAbort = "abort"
+)
+
+// inputReader reads the answer typed at a prompt, like the standard input parser of go-prompt.
+type inputReader interface {
+	Read() ([]byte, error)
+}
+
 var (
-	InitCmdName = "init"
-	initCmd = &cobra.Command{
+	InitCmdName    = "init"
+	initFromFile   string
+	nonInteractive bool
+	initForce      bool
+	initMerge      bool
+	// initPreset holds the keys set by flags, init doesn't ask for them
+	initPreset = make(map[config.Token]bool)
+	initCmd    = &cobra.Command{
 		Use:   InitCmdName,
 		Short: "Create config",
 		Args:  cobra.NoArgs,
@@ -22,264 +43,366 @@ var (
 	}
 )
 
+func init() {
+	for _, key := range config.Keys() {
+		if key.Flag != "" {
+			initCmd.Flags().String(key.Flag, "", fmt.Sprintf("(optional) %s, sets %s", key.Description, key.Name))
+		}
+	}
+
+	initCmd.Flags().StringVar(
+		&initFromFile,
+		"from-file",
+		"",
+		"(optional) merge a shared config file (e.g. from twig config export) before the other values",
+	)
+
+	initCmd.Flags().BoolVar(
+		&nonInteractive,
+		"non-interactive",
+		false,
+		"(optional) don't ask anything, values come from flags, --from-file, TWIG_* variables and defaults",
+	)
+
+	initCmd.Flags().BoolVar(
+		&initForce,
+		"force",
+		false,
+		"(optional) replace an existing config with the defaults",
+	)
+
+	initCmd.Flags().BoolVar(
+		&initMerge,
+		"merge",
+		false,
+		"(optional) keep an existing config and update it with the new values",
+	)
+
+	initCmd.MarkFlagsMutuallyExclusive("force", "merge")
+}
+
 func runInit(cmd *cobra.Command, args []string) {
-	input := prompt.NewStandardInputParser()
+	var input inputReader
+	if !nonInteractive {
+		input = prompt.NewStandardInputParser()
+	}
+
 	c := color.New(color.FgHiGreen)
 	cmdName := cmd.Name()
 
 	log.Debug().Println(fmt.Sprintf("%s: executing command", cmdName))
-	log.Warn().Println("Press \"ENTER\" to keep the default value")
 
 	if err := config.CreateConfigDir(); err != nil {
 		logCmdFatal(err)
 	}
 
-	if err := config.IsConfigExist(); err == nil {
-		if err = config.CreatConfigFile(); err != nil {
+	if err := prepareConfigFile(c, input); err != nil {
+		logCmdFatal(err)
+	}
+
+	config.InitConfig("", "")
+
+	if initFromFile != "" {
+		keys, err := config.Import(initFromFile)
+		if err != nil {
 			logCmdFatal(err)
 		}
+		log.Debug().Println(fmt.Sprintf("Imported %d key(s) from %q", len(keys), initFromFile))
 	}
 
-	config.InitConfig("")
-
-	log.Info().Println("\nProject Group")
-	if err := setHostFromInput(c, input); err != nil {
+	if err := setFromFlags(cmd); err != nil {
 		logCmdFatal(err)
 	}
 
-	if err := setEmailFromInput(c, input); err != nil {
-		logCmdFatal(err)
+	if nonInteractive {
+		if err := validateInitConfig(); err != nil {
+			logCmdFatal(err)
+		}
+
+		log.Info().Println("Setup complete. You're ready to go")
+		return
+	}
+
+	log.Warn().Println("Press \"ENTER\" to keep the default value")
+
+	log.Info().Println("\nProject Group")
+	for _, token := range []config.Token{config.ProjectHost, config.ProjectEmail} {
+		if err := setFromInput(c, input, token); err != nil {
+			logCmdFatal(err)
+		}
 	}
 
 	if err := setTokenFromInput(c, input); err != nil {
 		logCmdFatal(err)
 	}
 
-	if err := setAuthFromInput(c, input); err != nil {
+	if err := verifyCredentialsFromInput(c, input); err != nil {
 		logCmdFatal(err)
 	}
 
 	log.Info().Println("\nBranch Group")
-	if err := setBranchDefaultFromInput(c, input); err != nil {
-		logCmdFatal(err)
+	for _, key := range config.KeysOf(config.Branch) {
+		if err := setFromInput(c, input, key.Token); err != nil {
+			logCmdFatal(err)
+		}
 	}
 
-	if err := setBranchOriginFromInput(c, input); err != nil {
+	log.Info().Println("\nMapping Group")
+	isDiscovered, err := discoverMappingFromInput(c, input)
+	if err != nil {
 		logCmdFatal(err)
 	}
 
-	if err := setBranchExcludesFromInput(c, input); err != nil {
-		logCmdFatal(err)
+	if !isDiscovered {
+		if err = setMappingFromInput(c, input); err != nil {
+			logCmdFatal(err)
+		}
 	}
 
-	log.Info().Println("\nMapping Group")
-	if err := setMappingFromInput(c, input); err != nil {
-		logCmdFatal(err)
+	log.Info().Println("\nSetup complete. You're ready to go")
+}
+
+// validateInitConfig reports the problems of the config, --non-interactive can't ask for the missing values.
+func validateInitConfig() error {
+	problems := config.Validate()
+	if len(problems) == 0 {
+		return nil
 	}
 
-	log.Info().Println("\nSetup complete. You're ready to go")
+	printProblems(problems)
+	return fmt.Errorf("config has %d problem(s), pass the missing values as flags", len(problems))
 }
 
-func setHostFromInput(c *color.Color, in *prompt.PosixParser) error {
-	fmt.Print(c.Sprint("What is your JIRA host? (e.g. example.atlassian.net): "))
+// prepareConfigFile creates the config from the defaults, or decides what happens to an existing one.
+func prepareConfigFile(c *color.Color, in inputReader) error {
+	if !config.IsConfigExist() {
+		return config.CreatConfigFile()
+	}
 
-	str, err := in.Read()
+	mode, err := readInitMode(c, in)
 	if err != nil {
 		return err
 	}
 
-	value := strings.TrimSpace(string(str))
-	if value == "" {
-		return nil // skip, using default
+	switch mode {
+	case initModeForce:
+		log.Warn().Println(fmt.Sprintf("Replacing ~%s with the defaults", config.GetDefaultConfigPath()))
+		return config.CreatConfigFile()
+	case initModeMerge:
+		log.Debug().Println(fmt.Sprintf("Merging into ~%s", config.GetDefaultConfigPath()))
+		return nil
+	default:
+		return errors.New("config exist, abort")
 	}
-	if err = config.SetString(config.ProjectHost, value); err != nil {
-		return err
+}
+
+func readInitMode(c *color.Color, in inputReader) (string, error) {
+	switch {
+	case initForce:
+		return initModeForce, nil
+	case initMerge:
+		return initModeMerge, nil
+	case in == nil:
+		return "", fmt.Errorf("config ~%s exists, use --force to replace or --merge to update it", config.GetDefaultConfigPath())
 	}
-	log.Debug().Println(fmt.Sprintf("Input host: %q", value))
 
-	return nil
-}
+	for {
+		fmt.Print(c.Sprintf("Config ~%s exists. Replace, merge or abort? (%s/%s/%s): ", config.GetDefaultConfigPath(), initModeForce, initModeMerge, initModeAbort))
 
-func setEmailFromInput(c *color.Color, in *prompt.PosixParser) error {
-	fmt.Print(c.Sprint("What is your email in Jira? (e.g. example@exp.com): "))
+		str, err := in.Read()
+		if err != nil {
+			return "", err
+		}
 
-	str, err := in.Read()
-	if err != nil {
-		return err
+		switch value := strings.ToLower(strings.TrimSpace(string(str))); value {
+		case initModeForce, initModeMerge, initModeAbort:
+			return value, nil
+		default:
+			log.Error().Println(fmt.Sprintf("Invalid input. Please enter %q, %q or %q", initModeForce, initModeMerge, initModeAbort))
+		}
 	}
+}
 
-	value := strings.TrimSpace(string(str))
-	if value == "" {
-		return nil // skip, using default
-	}
-	if err = config.SetString(config.ProjectEmail, value); err != nil {
-		return err
-	}
-	log.Debug().Println(fmt.Sprintf("Input email: %q", value))
+// setFromFlags writes the values of the key flags, a plain token is kept in plain text.
+func setFromFlags(cmd *cobra.Command) error {
+	for _, key := range config.Keys() {
+		if key.Flag == "" || !cmd.Flags().Changed(key.Flag) {
+			continue
+		}
 
-	return nil
-}
+		value, err := cmd.Flags().GetString(key.Flag)
+		if err != nil {
+			return err
+		}
 
-func setTokenFromInput(c *color.Color, in *prompt.PosixParser) error {
-	fmt.Print(c.Sprint("What is your Jira PAT? (e.g. dXNlckBleGFtcGx): "))
+		if key.Secret && !secret.IsReference(value) {
+			log.Warn().Println(fmt.Sprintf("PAT is stored in plain text in %q", config.GetDefaultConfigPath()))
+		}
 
-	str, err := in.Read()
-	if err != nil {
-		return err
-	}
+		if err = config.Set(key.Token, value); err != nil {
+			return err
+		}
 
-	value := strings.TrimSpace(string(str))
-	if value == "" {
-		return nil // skip, using default
+		initPreset[key.Token] = true
 	}
-	if err = config.SetString(config.ProjectToken, value); err != nil {
-		return err
-	}
-	log.Debug().Println(fmt.Sprintf("Input token: %q", value))
 
 	return nil
 }
 
-func setAuthFromInput(c *color.Color, in *prompt.PosixParser) error {
+// setFromInput asks the prompt of the registered key until the value is valid or skipped.
+func setFromInput(c *color.Color, in inputReader, token config.Token) error {
+	key := config.Lookup(token)
+	if key.Prompt == "" || initPreset[token] {
+		return nil // not asked by init or set by a flag
+	}
+
 	for {
-		fmt.Print(c.Sprint("What is your Jira Auth type? (basic/bearer): "))
+		fmt.Print(c.Sprint(key.Question()))
 
 		str, err := in.Read()
 		if err != nil {
 			return err
 		}
 
-		value := strings.ToLower(string(str))
-		value = strings.TrimSpace(value)
-
-		switch value {
-		case network.BasicType, network.BearerType:
-			if err = config.SetString(config.ProjectAuth, value); err != nil {
-				return err
-			}
-			log.Debug().Println(fmt.Sprintf("Input auth: %q", value))
-			return nil
-		case "":
+		value := strings.TrimSpace(string(str))
+		if value == "" {
 			return nil // skip, using default
-		default:
-			msg := fmt.Sprintf("Invalid input. Please enter %q for Basic or %q for Bearer", network.BasicType, network.BearerType)
-			log.Error().Println(msg)
+		}
+
+		if err = config.Set(token, value); err != nil {
+			log.Error().Println(fmt.Sprintf("Invalid input. %s", err.Error()))
 			continue
 		}
+		log.Debug().Println(fmt.Sprintf("Input %s: %q", key.Name, value))
+
+		return nil
 	}
 }
 
-func setBranchDefaultFromInput(c *color.Color, in *prompt.PosixParser) error {
-	fmt.Print(c.Sprint("What branch do you use as default? (e.g. development): "))
+// verifyCredentialsFromInput signs in to Jira to detect the auth type. The credentials are asked again
+// until Jira accepts them or the check is skipped, then the auth type is asked instead.
+func verifyCredentialsFromInput(c *color.Color, in inputReader) error {
+	for {
+		auth, user, err := network.DetectAuth(&http.Client{Timeout: 30 * time.Second})
+		if err == nil {
+			if err = config.SetString(config.ProjectAuth, auth); err != nil {
+				return err
+			}
 
-	str, err := in.Read()
-	if err != nil {
-		return err
-	}
-	value := strings.TrimSpace(string(str))
-	if value == "" {
-		return nil // skip, using default
-	}
-	if err = config.SetString(config.BranchDefault, value); err != nil {
-		return err
-	}
-	log.Debug().Println(fmt.Sprintf("Input branch: %q", value))
+			log.Info().Println(fmt.Sprintf("Signed in as %s <%s> with %s auth", user.DisplayName, user.Email, auth))
+			return nil
+		}
 
-	return nil
-}
+		log.Error().Println(fmt.Sprintf("Unable to sign in to Jira:\n%s", err.Error()))
+		fmt.Print(c.Sprint("Enter the credentials again? (y/n, n skips the check): "))
 
-func setBranchOriginFromInput(c *color.Color, in *prompt.PosixParser) error {
-	fmt.Print(c.Sprint("What remote do you use as default? (e.g. origin): "))
+		str, err := in.Read()
+		if err != nil {
+			return err
+		}
 
-	str, err := in.Read()
-	if err != nil {
-		return err
-	}
-	value := strings.TrimSpace(string(str))
-	if value == "" {
-		return nil // skip, using default
-	}
-	if err = config.SetString(config.BranchOrigin, value); err != nil {
-		return err
-	}
-	log.Debug().Println(fmt.Sprintf("Input origin: %q", value))
+		if strings.ToLower(strings.TrimSpace(string(str))) != "y" {
+			log.Warn().Println("Credentials are not verified, run \"twig doctor\" once Jira is reachable")
+			return setFromInput(c, in, config.ProjectAuth)
+		}
 
-	return nil
+		// values passed as flags are asked too, they didn't work
+		for _, token := range []config.Token{config.ProjectHost, config.ProjectEmail, config.ProjectToken} {
+			delete(initPreset, token)
+		}
+
+		for _, token := range []config.Token{config.ProjectHost, config.ProjectEmail} {
+			if err = setFromInput(c, in, token); err != nil {
+				return err
+			}
+		}
+
+		if err = setTokenFromInput(c, in); err != nil {
+			return err
+		}
+	}
 }
 
-func setBranchExcludesFromInput(c *color.Color, in *prompt.PosixParser) error {
-	fmt.Print(c.Sprint("Exclude any words from the branch name? (e.g. be,mobile,web): "))
+func setTokenFromInput(c *color.Color, in inputReader) error {
+	if initPreset[config.ProjectToken] {
+		return nil // set by a flag
+	}
+
+	fmt.Print(c.Sprint(config.Lookup(config.ProjectToken).Question()))
 
 	str, err := in.Read()
 	if err != nil {
 		return err
 	}
+
 	value := strings.TrimSpace(string(str))
 	if value == "" {
 		return nil // skip, using default
 	}
 
-	valueArr := strings.Split(value, ",")
-	for i, _ := range valueArr {
-		// and trim space just in case
-		valueArr[i] = strings.TrimSpace(valueArr[i])
+	account := secret.Account{
+		Host: config.GetString(config.ProjectHost),
+		User: config.GetString(config.ProjectEmail),
 	}
-	if err = config.SetStringArray(config.BranchExclude, valueArr); err != nil {
-		return err
-	}
-	log.Debug().Println(fmt.Sprintf("Input exclude: %q", value))
-
-	return nil
-}
-
-func setMappingFromInput(c *color.Color, in *prompt.PosixParser) error {
-	log.Info().Println("Please input a valid id/ids for the following options:\nbuild, chore, ci, docs, feat, fix, pref, refactor, revert, style, test")
-
-	start := config.Mapping + 1
-	end := config.Mapping + 11
 
 	for {
-		if start > end {
-			return nil
-		}
-
-		option := strings.SplitAfter(config.FromToken(start), ".")[1]
-		fmt.Print(c.Sprintf("What should %q be mapped to? (e.g. 101 or 101,102): ", option))
-
-		str, err := in.Read()
+		reference, err := readTokenStoreFromInput(c, in)
 		if err != nil {
 			return err
 		}
 
-		value := strings.TrimSpace(string(str))
-		if value == "" {
-			start++
-			continue // skip, using default
+		if reference == "" {
+			log.Warn().Println(fmt.Sprintf("PAT is stored in plain text in %q", config.GetDefaultConfigPath()))
+			return config.SetString(config.ProjectToken, value)
 		}
 
-		valueArr := strings.Split(value, ",")
-		for i, _ := range valueArr {
-			// and trim space just in case
-			valueArr[i] = strings.TrimSpace(valueArr[i])
+		if err = secret.Store(reference, account, value); err != nil {
+			log.Error().Println(err.Error())
+			continue
 		}
 
-		hasIncorrectVal := false
-		for _, v := range valueArr {
-			// must check if input correct
-			if _, err = strconv.Atoi(v); err != nil {
-				hasIncorrectVal = true
-				break
-			}
+		log.Debug().Println(fmt.Sprintf("Input token stored in %q", reference))
+		return config.SetString(config.ProjectToken, reference)
+	}
+}
+
+// readTokenStoreFromInput returns a secret reference, or nothing when the token is kept in plain text.
+func readTokenStoreFromInput(c *color.Color, in inputReader) (string, error) {
+	for {
+		fmt.Print(c.Sprint("Where should the PAT be stored? (keyring/file/plain or helper:<command>, default keyring): "))
+
+		str, err := in.Read()
+		if err != nil {
+			return "", err
 		}
 
-		if hasIncorrectVal {
-			log.Error().Println("Invalid input. Please enter integer value or integer values split by comma")
+		value := strings.TrimSpace(string(str))
+
+		switch {
+		case value == "" || value == secret.SchemeKeyring:
+			return fmt.Sprintf("%s:%s", secret.SchemeKeyring, secret.DefaultKeyringService), nil
+		case value == secret.SchemeFile:
+			dir := path.Dir(config.GetDefaultConfigPath())
+			return fmt.Sprintf("%s:~%s/token", secret.SchemeFile, dir), nil
+		case value == "plain":
+			return "", nil
+		case secret.IsReference(value):
+			return value, nil
+		default:
+			log.Error().Println("Invalid input. Please enter \"keyring\", \"file\", \"plain\" or \"helper:<command>\"")
 			continue
 		}
+	}
+}
 
-		if err = config.SetStringArray(start, valueArr); err != nil {
-			log.Error().Println(err.Error())
+func setMappingFromInput(c *color.Color, in inputReader) error {
+	log.Info().Println(fmt.Sprintf("Please input a valid id/ids for the following options:\n%s", strings.Join(mappingNames(), ", ")))
+
+	for _, key := range config.KeysOf(config.Mapping) {
+		if err := setFromInput(c, in, key.Token); err != nil {
+			return err
 		}
-		start++
 	}
+
+	return nil
 }
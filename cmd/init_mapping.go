@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"slices"
+	"strconv"
+	"strings"
+	"twig/config"
+	"twig/issue"
+	"twig/log"
+	"twig/network"
+)
+
+const ignoredMapping = "-"
+
+// discoverMappingFromInput reads the issue types from Jira, proposes a mapping and lets the user edit it.
+// It returns false when the issue types can't be read, so the ids are asked one by one instead.
+func discoverMappingFromInput(c *color.Color, in inputReader) (bool, error) {
+	fmt.Print(c.Sprint("Which Jira project should issue types be read from? (e.g. ABC, empty for all): "))
+
+	str, err := in.Read()
+	if err != nil {
+		return false, err
+	}
+	projectKey := strings.ToUpper(strings.TrimSpace(string(str)))
+
+	api := newJiraApi()
+
+	var types []network.IssueType
+	if projectKey == "" {
+		types, err = api.GetJiraIssueTypes()
+	} else {
+		types, err = api.GetJiraProjectIssueTypes(projectKey)
+	}
+
+	if err != nil || len(types) == 0 {
+		log.Warn().Println(fmt.Sprintf("Unable to read issue types from Jira (%v), please input the ids manually", err))
+		return false, nil
+	}
+
+	types = uniqueIssueTypes(types)
+	assigned := issue.ProposeMapping(types, config.GetStringMap(config.Mapping))
+
+	for {
+		printIssueTypes(types, assigned)
+		fmt.Print(c.Sprintf("Press \"ENTER\" to accept, or select types and a mapping, e.g. \"1,4 feat\" or \"4 %s\" to ignore: ", ignoredMapping))
+
+		str, err := in.Read()
+		if err != nil {
+			return false, err
+		}
+
+		value := strings.TrimSpace(string(str))
+		if value == "" {
+			break
+		}
+
+		if err = selectMapping(value, types, assigned); err != nil {
+			log.Error().Println(fmt.Sprintf("Invalid input. %s", err.Error()))
+		}
+	}
+
+	return true, writeMapping(types, assigned)
+}
+
+// uniqueIssueTypes drops duplicates, Jira returns shared types once per project.
+func uniqueIssueTypes(types []network.IssueType) []network.IssueType {
+	result := make([]network.IssueType, 0, len(types))
+	for _, issueType := range types {
+		if !slices.ContainsFunc(result, func(t network.IssueType) bool { return t.Id == issueType.Id }) {
+			result = append(result, issueType)
+		}
+	}
+
+	return result
+}
+
+func printIssueTypes(types []network.IssueType, assigned map[string]string) {
+	log.Info().Println("Issue types found in Jira:")
+
+	for i, issueType := range types {
+		mapping := assigned[issueType.Id]
+		if mapping == "" {
+			mapping = ignoredMapping
+		}
+
+		log.Info().Println(fmt.Sprintf("%4d) %-8s %-24s -> %s", i+1, issueType.Id, issueType.Name, mapping))
+	}
+}
+
+// selectMapping applies input such as "1,4 feat" to the selected issue types.
+func selectMapping(input string, types []network.IssueType, assigned map[string]string) error {
+	fields := strings.Fields(strings.ReplaceAll(input, ",", " "))
+	if len(fields) < 2 {
+		return fmt.Errorf("enter the numbers of the types followed by a mapping")
+	}
+
+	mapping := fields[len(fields)-1]
+	if mapping != ignoredMapping && !slices.Contains(mappingNames(), mapping) {
+		return fmt.Errorf("%q is none of %s or %q", mapping, strings.Join(mappingNames(), ", "), ignoredMapping)
+	}
+
+	if mapping == ignoredMapping {
+		mapping = ""
+	}
+
+	selected := make([]string, 0, len(fields)-1)
+	for _, field := range fields[:len(fields)-1] {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(types) {
+			return fmt.Errorf("%q is not a number between 1 and %d", field, len(types))
+		}
+		selected = append(selected, types[n-1].Id)
+	}
+
+	for _, id := range selected {
+		assigned[id] = mapping
+	}
+
+	return nil
+}
+
+// writeMapping stores the ids of every mapping type, types without ids are ignored with "0".
+// Types passed as flags are kept.
+func writeMapping(types []network.IssueType, assigned map[string]string) error {
+	for _, key := range config.KeysOf(config.Mapping) {
+		if initPreset[key.Token] {
+			continue
+		}
+
+		name := strings.TrimPrefix(key.Name, config.FromToken(config.Mapping)+".")
+
+		ids := make([]string, 0)
+		for _, issueType := range types {
+			if assigned[issueType.Id] == name {
+				ids = append(ids, issueType.Id)
+			}
+		}
+
+		if len(ids) == 0 {
+			ids = []string{"0"}
+		}
+
+		if err := config.SetStringArray(key.Token, ids); err != nil {
+			return err
+		}
+		log.Debug().Println(fmt.Sprintf("Input %s: %q", key.Name, ids))
+	}
+
+	return nil
+}
+
+func mappingNames() []string {
+	keys := config.KeysOf(config.Mapping)
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = strings.TrimPrefix(key.Name, config.FromToken(config.Mapping)+".")
+	}
+
+	return names
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"github.com/fatih/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"twig/config"
+)
+
+// useInitHome moves the global config into a temporary home, with a config of the content unless it is empty.
+func useInitHome(t *testing.T, content string) string {
+	useTestConfig(t, testConfig)
+	t.Chdir(t.TempDir()) // outside of any repository
+
+	home := t.TempDir()
+	previous := config.SetHomeDir(home)
+	t.Cleanup(func() {
+		config.SetHomeDir(previous)
+	})
+
+	path := filepath.Join(home, config.GetDefaultConfigPath())
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if content == "" {
+		return path
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func useInitFlags(t *testing.T, force, merge, nonInteract bool, fromFile string) {
+	t.Cleanup(func() {
+		initForce, initMerge, nonInteractive, initFromFile = false, false, false, ""
+		initPreset = make(map[config.Token]bool)
+
+		for _, key := range config.Keys() {
+			if f := initCmd.Flags().Lookup(key.Flag); f != nil {
+				_ = f.Value.Set(f.DefValue)
+				f.Changed = false
+			}
+		}
+	})
+
+	initForce, initMerge, nonInteractive, initFromFile = force, merge, nonInteract, fromFile
+}
+
+// answers replays typed answers, one per prompt.
+type answers []string
+
+func (a *answers) Read() ([]byte, error) {
+	if len(*a) == 0 {
+		return nil, io.EOF
+	}
+
+	answer := (*a)[0]
+	*a = (*a)[1:]
+
+	return []byte(answer + "\n"), nil
+}
+
+func TestPrepareConfigFile(t *testing.T) {
+	const existing = "[branch]\ndefault = \"trunk\"\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		force    bool
+		merge    bool
+		answer   string
+		wantErr  bool
+		wantKept bool
+	}{
+		{name: "create"},
+		{name: "force", existing: existing, force: true},
+		{name: "merge", existing: existing, merge: true, wantKept: true},
+		{name: "non-interactive", existing: existing, wantErr: true, wantKept: true},
+		{name: "force from input", existing: existing, answer: initModeForce},
+		{name: "merge from input", existing: existing, answer: initModeMerge, wantKept: true},
+		{name: "abort from input", existing: existing, answer: initModeAbort, wantErr: true, wantKept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useInitHome(t, tt.existing)
+			useInitFlags(t, tt.force, tt.merge, tt.answer == "", "")
+
+			var in inputReader
+			if tt.answer != "" {
+				in = &answers{tt.answer}
+			}
+
+			err := prepareConfigFile(color.New(), in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf(`prepareConfigFile() = %v, want error %t`, err, tt.wantErr)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if subject := string(data); (subject == existing) != tt.wantKept {
+				t.Errorf(`config = %q, want the existing config kept %t`, subject, tt.wantKept)
+			}
+
+			if subject := string(data); !tt.wantKept && !strings.Contains(subject, "[project]") {
+				t.Errorf(`config = %q, want the defaults`, subject)
+			}
+		})
+	}
+}
+
+func TestSetFromFlags(t *testing.T) {
+	path := useInitHome(t, "")
+	useInitFlags(t, false, false, true, "")
+
+	if err := prepareConfigFile(color.New(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	config.InitConfig("", "")
+
+	for flag, value := range map[string]string{"host": "acme.atlassian.net", "email": "jane.roe@example.com"} {
+		if err := initCmd.Flags().Set(flag, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := setFromFlags(initCmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if subject := config.GetString(config.ProjectEmail); subject != "jane.roe@example.com" {
+		t.Errorf(`GetString(ProjectEmail) = %q, want match for the flag`, subject)
+	}
+
+	if !initPreset[config.ProjectHost] || !initPreset[config.ProjectEmail] || initPreset[config.ProjectToken] {
+		t.Errorf(`initPreset = %v, want host and email only`, initPreset)
+	}
+
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "acme.atlassian.net") {
+		t.Errorf(`config = %q, want the host written`, string(data))
+	}
+}
+
+func TestSetFromFlagsInvalid(t *testing.T) {
+	useInitHome(t, "")
+	useInitFlags(t, false, false, true, "")
+
+	if err := initCmd.Flags().Set("email", "jane.roe"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := setFromFlags(initCmd); err == nil {
+		t.Error(`setFromFlags(--email jane.roe) returned no error, want error for an invalid email`)
+	}
+}
+
+func TestValidateInitConfig(t *testing.T) {
+	useInitHome(t, "")
+	useInitFlags(t, false, false, true, "")
+
+	if err := prepareConfigFile(color.New(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	config.InitConfig("", "")
+
+	// host, email and token are missing in the defaults
+	if err := validateInitConfig(); err == nil {
+		t.Error(`validateInitConfig() returned no error, want error for the missing values`)
+	}
+}
+
+func TestRunInitFromFile(t *testing.T) {
+	path := useInitHome(t, "")
+
+	shared := filepath.Join(t.TempDir(), "shared.toml")
+	if err := os.WriteFile(shared, []byte("[branch]\ndefault = \"trunk\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	useInitFlags(t, false, false, true, shared)
+
+	if err := initCmd.Flags().Set("host", "acme.atlassian.net"); err != nil {
+		t.Fatal(err)
+	}
+
+	runInit(initCmd, nil)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject := string(data)
+	if !strings.Contains(subject, "trunk") || !strings.Contains(subject, "acme.atlassian.net") {
+		t.Errorf(`config = %q, want the shared default branch and the host of the flag`, subject)
+	}
+}
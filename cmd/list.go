@@ -0,0 +1,467 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"twig/config"
+	"twig/git"
+	"twig/log"
+	"twig/network"
+	"twig/util"
+)
+
+const (
+	summaryMaxLength = 40
+	outputCsv        = "csv"
+	sortBranch       = "branch"
+	sortIssue        = "issue"
+	sortStatus       = "status"
+	sortAssignee     = "assignee"
+	sortSprint       = "sprint"
+	sortDate         = "date"
+)
+
+var (
+	listRemote   bool
+	listSort     string
+	listStatus   string
+	listAssignee string
+	listSprint   string
+	listOutput   string
+	listCmdName  = "list"
+	listCmd      = &cobra.Command{
+		Use:         listCmdName,
+		Short:       "Lists branches with their Jira issues, upstream and last commit",
+		Args:        cobra.NoArgs,
+		Run:         runList,
+		Annotations: map[string]string{annotationRequiresGit: "true"},
+	}
+)
+
+// listEntry is a branch with the details of its first issue. Remote branches have neither an upstream nor a worktree.
+type listEntry struct {
+	git.BranchInfo
+	Remote   bool     `json:"remote,omitempty"`
+	Issues   []string `json:"issues,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Category string   `json:"statusCategory,omitempty"` // new, indeterminate or done
+	Assignee string   `json:"assignee,omitempty"`
+	Sprint   string   `json:"sprint,omitempty"`
+	Error    string   `json:"error,omitempty"` // the issue could not be fetched
+}
+
+func runList(cmd *cobra.Command, args []string) {
+	if err := validateListFlags(); err != nil {
+		logCmdFatal(err)
+	}
+
+	if listOutput != outputText {
+		// keep stdout clean for scripts, errors still go to stderr
+		log.SetLevel(log.ErrorLevel)
+		log.CreateRecorders()
+	}
+
+	log.Debug().Println("list: executing command")
+
+	entries, err := collectListEntries()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	entries = filterListEntries(entries)
+	sortListEntries(entries, listSort)
+
+	switch listOutput {
+	case outputJson:
+		err = printJson(entries)
+	case outputCsv:
+		err = writeListCsv(os.Stdout, entries)
+	default:
+		var table string
+		if table, err = renderListTable(entries); err == nil {
+			log.Info().Print(table)
+		}
+	}
+
+	if err != nil {
+		logCmdFatal(err)
+	}
+}
+
+// collectListEntries pairs the local and, with --remote, the remote branches with their issues.
+func collectListEntries() ([]*listEntry, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*listEntry, 0, len(branches))
+	upstreams := make(map[string]bool)
+
+	for _, b := range branches {
+		entries = append(entries, &listEntry{BranchInfo: b})
+		upstreams[b.Upstream] = true
+	}
+
+	if listRemote {
+		remoteBranches, err := repo.RemoteBranches(config.GetString(config.BranchOrigin))
+		if err != nil {
+			return nil, err
+		}
+
+		// remote branches with a local branch are already listed as its upstream
+		for _, b := range remoteBranches {
+			if !upstreams[b.Name] {
+				entries = append(entries, &listEntry{BranchInfo: b, Remote: true})
+			}
+		}
+	}
+
+	b, err := newIssueKeyBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	for _, entry := range entries {
+		if entry.Issues, err = b.ExtractIssueKeysFromBranch(entry.Name); err == nil {
+			keys = append(keys, entry.Issues...)
+		}
+	}
+
+	if len(keys) == 0 {
+		return entries, nil
+	}
+
+	jiraIssues, fetchErrs := queryIssues(detailsQuery(newJiraApi(), config.GetString(config.IssueSprint)), keys)
+
+	for _, entry := range entries {
+		if len(entry.Issues) > 0 {
+			entry.describe(entry.Issues[0], jiraIssues, fetchErrs)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(fetchErrs)) {
+		log.Warn().Println(fmt.Sprintf("%s: %s", key, fetchErrs[key].Error()))
+	}
+
+	return entries, nil
+}
+
+// detailsQuery fetches everything twig list shows.
+func detailsQuery(api network.JiraApi, sprintField string) issueQuery {
+	return issueQuery{
+		single: func(key string) (*network.JiraIssue, error) {
+			return api.GetJiraIssueDetails(key, sprintField)
+		},
+		bulk: func(fetcher *network.BulkFetcher, keys []string) ([]network.JiraIssue, error) {
+			return fetcher.FetchDetails(api, keys, sprintField)
+		},
+	}
+}
+
+func (e *listEntry) describe(key string, jiraIssues map[string]network.JiraIssue, fetchErrs map[string]error) {
+	if err, ok := fetchErrs[key]; ok {
+		e.Error = err.Error()
+		return
+	}
+
+	fields := jiraIssues[key].Fields
+
+	if fields.Summary != nil {
+		e.Summary = *fields.Summary
+	}
+
+	if fields.Status != nil {
+		e.Status = fields.Status.Name
+		e.Category = fields.Status.Category.Name
+	}
+
+	if fields.Assignee != nil {
+		e.Assignee = cmp.Or(fields.Assignee.DisplayName, fields.Assignee.Email)
+	}
+
+	if sprint := fields.Sprint(); sprint != nil {
+		e.Sprint = sprint.Name
+	}
+}
+
+func validateListFlags() error {
+	switch listOutput {
+	case outputText, outputJson, outputCsv:
+	default:
+		return fmt.Errorf("validate: unsupported output %q", listOutput)
+	}
+
+	switch listSort {
+	case sortBranch, sortIssue, sortStatus, sortAssignee, sortSprint, sortDate:
+		return nil
+	default:
+		return fmt.Errorf("validate: unsupported sort %q", listSort)
+	}
+}
+
+// filterListEntries keeps the entries matching every filter, values match case-insensitively
+// any part of the field. The status matches the status category as well, e.g. "done".
+func filterListEntries(entries []*listEntry) []*listEntry {
+	return slices.DeleteFunc(entries, func(e *listEntry) bool {
+		status := containsFold(e.Status, listStatus) || containsFold(e.Category, listStatus)
+		return !status || !containsFold(e.Assignee, listAssignee) || !containsFold(e.Sprint, listSprint)
+	})
+}
+
+func containsFold(s, substr string) bool {
+	if substr == "" {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// sortListEntries sorts by the column, entries without a value come last and ties are sorted by branch.
+func sortListEntries(entries []*listEntry, column string) {
+	slices.SortStableFunc(entries, func(a, b *listEntry) int {
+		var c int
+
+		switch column {
+		case sortIssue:
+			c = compareLast(firstIssue(a), firstIssue(b), compareIssueKeys)
+		case sortStatus:
+			c = compareLast(a.Status, b.Status, strings.Compare)
+		case sortAssignee:
+			c = compareLast(a.Assignee, b.Assignee, strings.Compare)
+		case sortSprint:
+			c = compareLast(a.Sprint, b.Sprint, strings.Compare)
+		case sortDate:
+			c = b.Date.Compare(a.Date) // newest first
+		}
+
+		return cmp.Or(c, cmp.Compare(a.Name, b.Name))
+	})
+}
+
+func compareLast(a, b string, compare func(a, b string) int) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	default:
+		return compare(a, b)
+	}
+}
+
+// compareIssueKeys sorts by project and then by number, so ABC-9 comes before ABC-10.
+func compareIssueKeys(a, b string) int {
+	projectA, numberA, _ := strings.Cut(a, "-")
+	projectB, numberB, _ := strings.Cut(b, "-")
+
+	na, _ := strconv.Atoi(numberA)
+	nb, _ := strconv.Atoi(numberB)
+
+	return cmp.Or(strings.Compare(projectA, projectB), cmp.Compare(na, nb))
+}
+
+func firstIssue(e *listEntry) string {
+	if len(e.Issues) == 0 {
+		return ""
+	}
+
+	return e.Issues[0]
+}
+
+// renderListTable marks the current branch with "*" and branches checked out in other worktrees with "+", like git branch.
+// Every issue and status is colored, so the escape codes add the same width to every row of the table.
+func renderListTable(entries []*listEntry) (string, error) {
+	var buffer strings.Builder
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, " \tBRANCH\tISSUE\tSUMMARY\tSTATUS\tASSIGNEE\tSPRINT\tUPSTREAM\tAHEAD\tBEHIND\tLAST COMMIT\tWORKTREE")
+	for _, e := range entries {
+		marker := ""
+		if e.IsCurrent {
+			marker = "*"
+		} else if e.CheckedOutElsewhere() {
+			marker = "+"
+		}
+
+		upstream := e.Upstream
+		if e.Gone {
+			upstream = fmt.Sprintf("%s (gone)", upstream)
+		}
+
+		worktree := ""
+		if e.CheckedOutElsewhere() {
+			worktree = e.Worktree
+		}
+
+		status := e.Status
+		if e.Error != "" {
+			status = string(outcomeFetchError)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			marker,
+			e.Name,
+			util.Colorize(color.FgCyan, strings.Join(e.Issues, ",")),
+			truncate(e.Summary, summaryMaxLength),
+			util.Colorize(statusColor(e), status),
+			e.Assignee,
+			e.Sprint,
+			upstream,
+			e.Ahead,
+			e.Behind,
+			formatDate(e.Date),
+			worktree,
+		)
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func statusColor(e *listEntry) color.Attribute {
+	switch {
+	case e.Error != "":
+		return color.FgRed
+	case e.Category == "done":
+		return color.FgGreen
+	case e.Category == "indeterminate":
+		return color.FgYellow
+	case e.Category == "new":
+		return color.FgBlue
+	default:
+		return color.FgWhite
+	}
+}
+
+func writeListCsv(out io.Writer, entries []*listEntry) error {
+	w := csv.NewWriter(out)
+
+	_ = w.Write([]string{
+		"branch", "remote", "current", "upstream", "ahead", "behind", "gone", "sha", "date", "author", "worktree",
+		"issues", "summary", "status", "status_category", "assignee", "sprint", "error",
+	})
+
+	for _, e := range entries {
+		date := ""
+		if !e.Date.IsZero() {
+			date = e.Date.Format(time.RFC3339)
+		}
+
+		_ = w.Write([]string{
+			e.Name,
+			strconv.FormatBool(e.Remote),
+			strconv.FormatBool(e.IsCurrent),
+			e.Upstream,
+			strconv.Itoa(e.Ahead),
+			strconv.Itoa(e.Behind),
+			strconv.FormatBool(e.Gone),
+			e.SHA,
+			date,
+			e.Author,
+			e.Worktree,
+			strings.Join(e.Issues, ","),
+			e.Summary,
+			e.Status,
+			e.Category,
+			e.Assignee,
+			e.Sprint,
+			e.Error,
+		})
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+
+	return string(runes[:length-1]) + "…"
+}
+
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	return date.Local().Format(time.DateTime)
+}
+
+func init() {
+	listCmd.Flags().BoolVarP(
+		&listRemote,
+		"remote",
+		"r",
+		false,
+		fmt.Sprintf(
+			"(optional) list the branches of %s without a local branch as well",
+			config.FromToken(config.BranchOrigin),
+		),
+	)
+
+	listCmd.Flags().StringVar(
+		&listSort,
+		"sort",
+		sortBranch,
+		fmt.Sprintf(
+			"(optional) column to sort by: %s",
+			strings.Join([]string{sortBranch, sortIssue, sortStatus, sortAssignee, sortSprint, sortDate}, ", "),
+		),
+	)
+
+	listCmd.Flags().StringVar(
+		&listStatus,
+		"status",
+		"",
+		"(optional) list only branches whose issue status or status category contains the value, e.g. done",
+	)
+
+	listCmd.Flags().StringVar(
+		&listAssignee,
+		"assignee",
+		"",
+		"(optional) list only branches whose issue assignee contains the value",
+	)
+
+	listCmd.Flags().StringVar(
+		&listSprint,
+		"sprint",
+		"",
+		"(optional) list only branches whose issue sprint contains the value",
+	)
+
+	listCmd.Flags().StringVarP(
+		&listOutput,
+		"output",
+		"o",
+		outputText,
+		fmt.Sprintf("(optional) output format: %s, %s or %s", outputText, outputJson, outputCsv),
+	)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"twig/git"
+	"twig/git/gittest"
+	"twig/network"
+)
+
+func useListFlags(t *testing.T, remote bool) {
+	t.Cleanup(func() {
+		listRemote, listSort, listStatus, listAssignee, listSprint, listOutput = false, sortBranch, "", "", "", outputText
+	})
+
+	listRemote, listSort, listStatus, listAssignee, listSprint, listOutput = remote, sortBranch, "", "", "", outputText
+}
+
+func newListedIssue(key, status, category, assignee, sprint string) network.JiraIssue {
+	jiraIssue := newJiraIssue(key, "10001", "Summary of "+key)
+	jiraIssue.Fields.Status = &network.IssueStatus{Name: status, Category: network.IssueStatusCategory{Name: category}}
+	jiraIssue.Fields.Assignee = &network.IssueAssignee{DisplayName: assignee}
+	jiraIssue.Fields.Sprints = []network.IssueSprint{{Name: sprint, State: "active"}}
+
+	return jiraIssue
+}
+
+func listedNames(entries []*listEntry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+
+	return names
+}
+
+func TestCollectListEntries(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useListFlags(t, true)
+
+	repo := gittest.New("development", "feat/ABC-1_login", "fix/ABC-2_logout")
+	repo.Info["feat/ABC-1_login"] = git.BranchInfo{Upstream: "origin/feat/ABC-1_login"}
+	repo.Tracking["origin"] = []string{"development", "feat/ABC-1_login", "feat/ABC-3_signup"}
+
+	useFakes(t, repo, newFakeJiraApi(
+		newListedIssue("ABC-1", "In Progress", "indeterminate", "John Doe", "Sprint 7"),
+		newListedIssue("ABC-3", "To Do", "new", "Jane Roe", "Sprint 8"),
+	))
+
+	subject, err := collectListEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"development", "feat/ABC-1_login", "fix/ABC-2_logout", "origin/development", "origin/feat/ABC-3_signup"}
+	if names := listedNames(subject); !slices.Equal(names, want) {
+		t.Fatalf(`collectListEntries() = %q, want match for %q`, names, want)
+	}
+
+	if e := subject[1]; e.Summary != "Summary of ABC-1" || e.Status != "In Progress" || e.Assignee != "John Doe" || e.Sprint != "Sprint 7" {
+		t.Errorf(`collectListEntries()[1] = %+v, want details of ABC-1`, e)
+	}
+
+	if e := subject[2]; e.Error == "" || e.Status != "" {
+		t.Errorf(`collectListEntries()[2] = %+v, want error for the missing ABC-2`, e)
+	}
+
+	if e := subject[4]; !e.Remote || e.Assignee != "Jane Roe" {
+		t.Errorf(`collectListEntries()[4] = %+v, want remote branch of ABC-3`, e)
+	}
+}
+
+func TestFilterAndSortListEntries(t *testing.T) {
+	useListFlags(t, false)
+
+	entries := []*listEntry{
+		{BranchInfo: git.BranchInfo{Name: "feat/ABC-10_a"}, Issues: []string{"ABC-10"}, Status: "Done", Category: "done", Assignee: "John Doe"},
+		{BranchInfo: git.BranchInfo{Name: "feat/ABC-9_b"}, Issues: []string{"ABC-9"}, Status: "In Review", Category: "indeterminate", Assignee: "John Doe"},
+		{BranchInfo: git.BranchInfo{Name: "development"}},
+		{BranchInfo: git.BranchInfo{Name: "fix/ABC-2_c"}, Issues: []string{"ABC-2"}, Status: "Closed", Category: "done", Assignee: "Jane Roe"},
+	}
+
+	sortListEntries(entries, sortIssue)
+
+	want := []string{"fix/ABC-2_c", "feat/ABC-9_b", "feat/ABC-10_a", "development"}
+	if names := listedNames(entries); !slices.Equal(names, want) {
+		t.Errorf(`sortListEntries(issue) = %q, want match for %q`, names, want)
+	}
+
+	listStatus, listAssignee = "DONE", "john"
+
+	want = []string{"feat/ABC-10_a"}
+	if names := listedNames(filterListEntries(entries)); !slices.Equal(names, want) {
+		t.Errorf(`filterListEntries() = %q, want match for %q`, names, want)
+	}
+}
+
+func TestRenderListTable(t *testing.T) {
+	entries := []*listEntry{
+		{BranchInfo: git.BranchInfo{Name: "development", Upstream: "origin/development", IsCurrent: true, Worktree: "/src/twig"}},
+		{
+			BranchInfo: git.BranchInfo{Name: "feat/ABC-1_login", Upstream: "origin/feat/ABC-1_login", Ahead: 2, Behind: 1, Worktree: "/src/login", Date: time.Now()},
+			Issues:     []string{"ABC-1"},
+			Summary:    "Add the login page with remember me and social logins",
+			Status:     "In Progress",
+			Sprint:     "Sprint 7",
+		},
+		{BranchInfo: git.BranchInfo{Name: "fix/ABC-2_logout", Upstream: "origin/fix/ABC-2_logout", Gone: true}, Issues: []string{"ABC-2"}, Error: "not found"},
+	}
+
+	subject, err := renderListTable(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(subject), "\n")
+	if len(lines) != 4 {
+		t.Fatalf(`renderListTable() = %q, want header and 3 branches`, subject)
+	}
+
+	want := []string{"* ", "+ ", "  2 ", "/src/login", "(gone)", "In Progress", "Sprint 7", "Add the login page with remember me and…", "fetch-error"}
+	for _, s := range want {
+		if !strings.Contains(subject, s) {
+			t.Errorf(`renderListTable() = %q, want match for %q`, subject, s)
+		}
+	}
+
+	if strings.Contains(lines[1], "/src/twig") {
+		t.Errorf(`renderListTable() = %q, want no worktree of the current branch`, lines[1])
+	}
+}
+
+func TestWriteListCsv(t *testing.T) {
+	entries := []*listEntry{
+		{BranchInfo: git.BranchInfo{Name: "feat/ABC-1_login"}, Issues: []string{"ABC-1", "ABC-2"}, Summary: `Say "hi", then login`},
+	}
+
+	var buffer strings.Builder
+	if err := writeListCsv(&buffer, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `feat/ABC-1_login,false,false,,0,0,false,,,,,"ABC-1,ABC-2","Say ""hi"", then login",,,,,`
+	if lines := strings.Split(strings.TrimSpace(buffer.String()), "\n"); len(lines) != 2 || lines[1] != want {
+		t.Errorf(`writeListCsv() = %q, want match for %q`, buffer.String(), want)
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"net/http"
+	"slices"
+	"strings"
+	"twig/common"
+	"twig/config"
+	"twig/git"
+	"twig/hosting"
+	"twig/log"
+	"twig/network"
+	"twig/secret"
+)
+
+var (
+	prBase    string
+	prPush    bool
+	prCmdName = "pr"
+	prCmd     = &cobra.Command{
+		Use:         prCmdName,
+		Short:       "Opens a pull request of the current branch titled after its Jira Issue",
+		Args:        cobra.NoArgs,
+		Run:         runPr,
+		Annotations: map[string]string{annotationRequiresGit: "true"},
+	}
+)
+
+func init() {
+	prCmd.Flags().StringVarP(
+		&prBase,
+		"base",
+		"b",
+		"",
+		fmt.Sprintf("(optional) branch to merge into, defaults to %s", config.FromToken(config.BranchDefault)),
+	)
+
+	prCmd.Flags().BoolVarP(
+		&prPush,
+		"push",
+		"p",
+		false,
+		"(optional) push the branch to the remote first",
+	)
+}
+
+func runPr(cmd *cobra.Command, args []string) {
+	log.Debug().Println("pr: executing command")
+
+	repo, err := openRepository()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	name, err := repo.CurrentBranch()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	b, err := newIssueKeyBranch()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	key, err := b.ExtractIssueNameFromBranch(name)
+	if err != nil {
+		logCmdFatal(fmt.Errorf("branch %q: %w", name, err))
+	}
+
+	base := prBase
+	if base == "" {
+		base = config.GetString(config.BranchDefault)
+	}
+
+	if base == name {
+		logCmdFatal(fmt.Errorf("branch %q can not be merged into itself, use --base", name))
+	}
+
+	remote := config.GetString(config.BranchOrigin)
+	if err := pushPrBranch(repo, remote, name); err != nil {
+		logCmdFatal(err)
+	}
+
+	provider, err := newPullRequestProvider(repo, remote)
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	jiraIssue, err := newJiraApi().GetJiraIssueDescription(key)
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	if jiraIssue.Fields.Summary == nil {
+		logCmdFatal(fmt.Errorf("%s: issue summary is missing", key))
+	}
+
+	pr := hosting.PullRequest{
+		Title:     *jiraIssue.Fields.Summary,
+		Body:      buildPullRequestBody(config.GetString(config.PrTemplate), key, *jiraIssue),
+		Head:      name,
+		Base:      base,
+		Reviewers: config.GetStringArray(config.PrReviewers),
+	}
+
+	log.Info().Println(fmt.Sprintf("Open a pull request of %q into %q", name, base))
+
+	url, err := provider.Open(pr)
+	if url != "" {
+		fmt.Println(url)
+	}
+
+	if err != nil {
+		logCmdFatal(err)
+	}
+}
+
+// pushPrBranch pushes the branch with --push, otherwise the branch must have been pushed already,
+// to a branch of the same name on the remote and without commits left to push.
+func pushPrBranch(repo git.Repository, remote, name string) error {
+	if prPush {
+		pushCommand, err := common.PushToRemote(repo, name, remote)
+		if pushCommand != "" {
+			log.Info().Println(pushCommand)
+		}
+		return err
+	}
+
+	branches, err := repo.Branches()
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(branches, func(lb git.BranchInfo) bool { return lb.Name == name })
+	if i == -1 || branches[i].Gone || branches[i].Upstream != fmt.Sprintf("%s/%s", remote, name) {
+		return fmt.Errorf("branch %q is not on %q, push it or use --push", name, remote)
+	}
+
+	if branches[i].Ahead > 0 {
+		return fmt.Errorf("branch %q has %d commit(s) not pushed to %q, push it or use --push", name, branches[i].Ahead, remote)
+	}
+
+	return nil
+}
+
+// newPullRequestProvider returns the hosting of the remote along with the token of pr.token.
+func newPullRequestProvider(repo git.Repository, remote string) (hosting.Provider, error) {
+	remoteUrl, err := repo.RemoteURL(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	hostRepo, err := hosting.ParseRemote(remoteUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := config.GetString(config.PrToken)
+	if reference == "" {
+		return nil, fmt.Errorf("%q is not set, run twig config set %s <token> or export %s",
+			config.FromToken(config.PrToken), config.FromToken(config.PrToken), config.EnvName(config.PrToken))
+	}
+
+	token, err := secret.Resolve(reference, secret.Account{Host: hostRepo.Host, User: config.GetString(config.ProjectEmail)})
+	if err != nil {
+		return nil, fmt.Errorf("%q %w", config.FromToken(config.PrToken), err)
+	}
+
+	provider := config.GetString(config.PrProvider)
+	if provider == "auto" {
+		provider = ""
+	}
+
+	options := hosting.Options{
+		Provider: provider,
+		Api:      config.GetString(config.PrApi),
+		Token:    token,
+	}
+
+	return hosting.New(hostRepo, options, &http.Client{})
+}
+
+// buildPullRequestBody fills pr.template, the issue link points to the Jira host of the config.
+func buildPullRequestBody(template, key string, jiraIssue network.JiraIssue) string {
+	summary, description := "", ""
+	if jiraIssue.Fields.Summary != nil {
+		summary = *jiraIssue.Fields.Summary
+	}
+	if jiraIssue.Fields.Description != nil {
+		description = *jiraIssue.Fields.Description
+	}
+
+	replacer := strings.NewReplacer(
+		"{key}", key,
+		"{url}", fmt.Sprintf("https://%s/browse/%s", config.GetString(config.ProjectHost), key),
+		"{summary}", summary,
+		"{description}", description,
+		// newlines are escaped when set on the command line or in TWIG_PR_TEMPLATE
+		`\n`, "\n",
+	)
+
+	return strings.TrimSpace(replacer.Replace(template))
+}
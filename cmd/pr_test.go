@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"twig/git"
+	"twig/git/gittest"
+	"twig/network"
+)
+
+// newGitHubServer stands in for the API of GitHub and keeps the pull requests it was sent.
+func newGitHubServer(t *testing.T) (*httptest.Server, *[]map[string]any) {
+	pulls := make([]map[string]any, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/repos/acme/twig/pulls":
+			pulls = append(pulls, body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number": 1, "html_url": "https://github.com/acme/twig/pull/1"}`))
+		case "/repos/acme/twig/pulls/1/requested_reviewers":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	t.Cleanup(server.Close)
+	return server, &pulls
+}
+
+// prTestConfig adds the Jira host the issue links point to.
+var prTestConfig = strings.Replace(testConfig, "[project]\n", "[project]\nhost = \"acme.atlassian.net\"\n", 1)
+
+func usePrConfig(t *testing.T, server *httptest.Server) {
+	useTestConfig(t, prTestConfig+fmt.Sprintf(`
+[pr]
+api = %q
+token = "secret"
+reviewers = ["jane"]
+`, server.URL))
+}
+
+func usePrFlags(t *testing.T, base string, push bool) {
+	t.Cleanup(func() {
+		prBase, prPush = "", false
+	})
+
+	prBase, prPush = base, push
+}
+
+func newPrRepository(branch string) *gittest.Repository {
+	repo := gittest.New(branch, "development")
+	repo.URLs["origin"] = "git@github.com:acme/twig.git"
+
+	return repo
+}
+
+func newDescribedIssue(key, summary, description string) network.JiraIssue {
+	jiraIssue := newJiraIssue(key, "10002", summary)
+	jiraIssue.Fields.Description = &description
+
+	return jiraIssue
+}
+
+func TestRunPr(t *testing.T) {
+	server, pulls := newGitHubServer(t)
+	usePrConfig(t, server)
+	usePrFlags(t, "", true)
+
+	repo := newPrRepository("fix/ABC-1_handle-null-token")
+	useFakes(t, repo, newFakeJiraApi(newDescribedIssue("ABC-1", "Handle null token", "Login fails without a token.")))
+
+	runPr(prCmd, nil)
+
+	if len(*pulls) != 1 {
+		t.Fatalf(`runPr() sent %d pull requests, want 1`, len(*pulls))
+	}
+
+	pr := (*pulls)[0]
+	if pr["title"] != "Handle null token" || pr["head"] != "fix/ABC-1_handle-null-token" || pr["base"] != "development" {
+		t.Errorf(`runPr() = %v, want the pull request of ABC-1 into development`, pr)
+	}
+
+	if want := "[ABC-1](https://acme.atlassian.net/browse/ABC-1)\n\nLogin fails without a token."; pr["body"] != want {
+		t.Errorf(`runPr() body = %q, want match for %q`, pr["body"], want)
+	}
+
+	if !slices.Equal(repo.Commands, []string{"push -u origin fix/ABC-1_handle-null-token"}) {
+		t.Errorf(`runPr() = %q, want the branch pushed`, repo.Commands)
+	}
+}
+
+func TestRunPrBase(t *testing.T) {
+	server, pulls := newGitHubServer(t)
+	usePrConfig(t, server)
+	usePrFlags(t, "release/1.0", false)
+
+	repo := newPrRepository("fix/ABC-1_handle-null-token")
+	repo.Info["fix/ABC-1_handle-null-token"] = git.BranchInfo{Upstream: "origin/fix/ABC-1_handle-null-token"}
+	useFakes(t, repo, newFakeJiraApi(newDescribedIssue("ABC-1", "Handle null token", "")))
+
+	runPr(prCmd, nil)
+
+	if len(*pulls) != 1 || (*pulls)[0]["base"] != "release/1.0" || (*pulls)[0]["body"] != "[ABC-1](https://acme.atlassian.net/browse/ABC-1)" {
+		t.Errorf(`runPr(--base release/1.0) = %v, want the pull request into release/1.0`, *pulls)
+	}
+
+	if len(repo.Commands) != 0 {
+		t.Errorf(`runPr() = %q, want no push`, repo.Commands)
+	}
+}
+
+func TestPushPrBranchNotPushed(t *testing.T) {
+	usePrFlags(t, "", false)
+
+	repo := newPrRepository("fix/ABC-1_handle-null-token")
+
+	if err := pushPrBranch(repo, "origin", "fix/ABC-1_handle-null-token"); err == nil {
+		t.Error(`pushPrBranch() returned no error, want error for a branch without upstream`)
+	}
+}
+
+func TestPushPrBranchOutdated(t *testing.T) {
+	usePrFlags(t, "", false)
+
+	tests := map[string]git.BranchInfo{
+		"other upstream":   {Upstream: "origin/development"},
+		"unpushed commits": {Upstream: "origin/fix/ABC-1_handle-null-token", Ahead: 2},
+	}
+
+	for name, info := range tests {
+		repo := newPrRepository("fix/ABC-1_handle-null-token")
+		repo.Info["fix/ABC-1_handle-null-token"] = info
+
+		if err := pushPrBranch(repo, "origin", "fix/ABC-1_handle-null-token"); err == nil {
+			t.Errorf(`pushPrBranch() returned no error, want error for a branch with %s`, name)
+		}
+	}
+}
+
+func TestBuildPullRequestBody(t *testing.T) {
+	useTestConfig(t, prTestConfig)
+
+	jiraIssue := newDescribedIssue("ABC-1", "Handle null token", "Login fails.")
+
+	subject := buildPullRequestBody(`{summary}\nCloses [{key}]({url})\n\n{description}`, "ABC-1", jiraIssue)
+	if want := "Handle null token\nCloses [ABC-1](https://acme.atlassian.net/browse/ABC-1)\n\nLogin fails."; subject != want {
+		t.Errorf(`buildPullRequestBody() = %q, want match for %q`, subject, want)
+	}
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"slices"
+	"strings"
+	"twig/branch"
+	"twig/common"
+	"twig/config"
+	"twig/git"
+	"twig/log"
+	"twig/network"
+)
+
+var (
+	renameAll     bool
+	renameRemote  bool
+	renameCmdName = "rename"
+	renameCmd     = &cobra.Command{
+		Use:         renameCmdName,
+		Short:       "Renames branches after their Jira Issue was retitled or retyped",
+		Args:        cobra.MaximumNArgs(1),
+		Run:         runRename,
+		Annotations: map[string]string{annotationRequiresGit: "true"},
+	}
+)
+
+func init() {
+	renameCmd.Flags().BoolVar(
+		&renameAll,
+		"all",
+		false,
+		fmt.Sprintf(
+			"(optional) rename every local branch of a Jira issue, except branches matching %s",
+			config.FromToken(config.CleanProtected),
+		),
+	)
+
+	renameCmd.Flags().BoolVarP(
+		&renameRemote,
+		"remote",
+		"r",
+		false,
+		fmt.Sprintf(
+			"(optional) rename the upstream on %s as well and track it",
+			config.FromToken(config.BranchOrigin),
+		),
+	)
+}
+
+func runRename(cmd *cobra.Command, args []string) {
+	log.Debug().Println("rename: executing command")
+
+	if renameAll && len(args) > 0 {
+		logCmdFatal(errors.New("validate: either a branch or --all"))
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	branches, err := selectRenameBranches(repo, args)
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	if len(branches) == 0 {
+		logCmdFatal(errors.New("no branches related to Jira issues were found"))
+	}
+
+	api := newJiraApi()
+
+	jiraIssueTypes, err := api.GetJiraIssueTypes()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	keys := make([]string, len(branches))
+	for i, b := range branches {
+		keys[i] = b.issue
+	}
+
+	jiraIssues, fetchErrs := queryIssues(detailsQuery(api, ""), keys)
+
+	failed := 0
+	for _, b := range branches {
+		if err, ok := fetchErrs[b.issue]; ok {
+			log.Error().Println(fmt.Sprintf("%s: %s: %s", b.Name, b.issue, err.Error()))
+			failed++
+			continue
+		}
+
+		if err := renameBranch(repo, b, jiraIssues[b.issue], jiraIssueTypes); err != nil {
+			log.Error().Println(fmt.Sprintf("%s: %s", b.Name, err.Error()))
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		logCmdFatal(fmt.Errorf("failed to rename %d branch(es)", failed))
+	}
+}
+
+// renameTarget is a local branch with the issue its new name is built from.
+type renameTarget struct {
+	git.BranchInfo
+	issue string
+}
+
+// selectRenameBranches returns the branch given as argument, the current one or, with --all,
+// every branch of an issue which is neither protected nor checked out in another worktree.
+// Branches of several issues are left out, see extractRenameIssue.
+func selectRenameBranches(repo git.Repository, args []string) ([]renameTarget, error) {
+	localBranches, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := newIssueKeyBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	if !renameAll {
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		} else if name, err = repo.CurrentBranch(); err != nil {
+			return nil, err
+		}
+
+		i := slices.IndexFunc(localBranches, func(lb git.BranchInfo) bool { return lb.Name == name })
+		if i == -1 {
+			return nil, fmt.Errorf("branch %q not found", name)
+		}
+
+		issue, err := extractRenameIssue(b, name)
+		if err != nil {
+			return nil, fmt.Errorf("branch %q: %w", name, err)
+		}
+
+		return []renameTarget{{BranchInfo: localBranches[i], issue: issue}}, nil
+	}
+
+	protected := slices.Concat(config.GetStringArray(config.CleanProtected), []string{config.GetString(config.BranchDefault)})
+
+	targets := make([]renameTarget, 0)
+	for _, localBranch := range localBranches {
+		if branch.IsProtected(localBranch.Name, protected) {
+			continue
+		}
+
+		issue, err := extractRenameIssue(b, localBranch.Name)
+		if errors.Is(err, errSeveralIssues) {
+			log.Warn().Println(fmt.Sprintf("Skip %q which %s", localBranch.Name, err.Error()))
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if localBranch.CheckedOutElsewhere() {
+			log.Warn().Println(fmt.Sprintf("Skip %q checked out in %q", localBranch.Name, localBranch.Worktree))
+			continue
+		}
+
+		targets = append(targets, renameTarget{BranchInfo: localBranch, issue: issue})
+	}
+
+	return targets, nil
+}
+
+var errSeveralIssues = errors.New("names several issues")
+
+// extractRenameIssue returns the only issue key of the branch. A new name is built from a single
+// issue, so renaming a branch of several issues would drop all but one of its keys.
+func extractRenameIssue(b *branch.Branch, name string) (string, error) {
+	keys, err := b.ExtractIssueKeysFromBranch(name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(keys) > 1 {
+		return "", fmt.Errorf("%w %s, rename it by hand to keep every key", errSeveralIssues, strings.Join(keys, ", "))
+	}
+
+	return keys[0], nil
+}
+
+// renameBranch renames the branch after its issue. With --remote a branch tracking the remote branch
+// of the same name is pushed under the new name, which becomes its upstream, and the old one is deleted.
+func renameBranch(repo git.Repository, target renameTarget, jiraIssue network.JiraIssue, jiraIssueTypes []network.IssueType) error {
+	newName, err := buildRenamedBranch(target.Name, jiraIssue, jiraIssueTypes)
+	if err != nil {
+		return err
+	}
+
+	if newName == target.Name {
+		log.Info().Println(fmt.Sprintf("Branch %q is up to date", target.Name))
+		return nil
+	}
+
+	if repo.HasBranch(newName) {
+		return fmt.Errorf("a branch named %q already exists", newName)
+	}
+
+	log.Info().Println(fmt.Sprintf("Rename %q to %q", target.Name, newName))
+
+	if out, err := repo.RenameBranch(target.Name, newName); err != nil {
+		return fmt.Errorf("%s %w", out, err)
+	}
+
+	remote := config.GetString(config.BranchOrigin)
+	if !renameRemote || target.Gone || target.Upstream != fmt.Sprintf("%s/%s", remote, target.Name) {
+		return nil
+	}
+
+	pushCommand, err := common.PushToRemote(repo, newName, remote)
+	if err != nil {
+		return err
+	}
+
+	if pushCommand != "" {
+		log.Info().Println(pushCommand)
+	}
+
+	return deleteRemoteBranch(repo, remote, target.Name)
+}
+
+// buildRenamedBranch builds the name like twig create does. A branch keeps its type
+// if the issue type is not mapped, e.g. it was created with --type.
+func buildRenamedBranch(name string, jiraIssue network.JiraIssue, jiraIssueTypes []network.IssueType) (string, error) {
+	if jiraIssue.Fields.Type == nil || jiraIssue.Fields.Summary == nil {
+		return "", fmt.Errorf("%s: issue type or summary is missing", jiraIssue.Key)
+	}
+
+	bt, err := convertIssueTypeToBranchType(*jiraIssue.Fields.Type, jiraIssueTypes)
+	if err != nil {
+		log.Debug().Println(fmt.Sprintf("Issue %q: %s, keeping the branch type", jiraIssue.Key, err.Error()))
+
+		if bt, err = branch.TypeFromBranch(name); err != nil {
+			return "", err
+		}
+	}
+
+	return branch.New(bt, config.GetStringArray(config.BranchExclude)).BuildName(jiraIssue), nil
+}
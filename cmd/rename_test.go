@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"twig/git"
+	"twig/git/gittest"
+)
+
+func useRenameFlags(t *testing.T, all, remote bool) {
+	t.Cleanup(func() {
+		renameAll, renameRemote = false, false
+	})
+
+	renameAll, renameRemote = all, remote
+}
+
+func TestRunRenameCurrentBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useRenameFlags(t, false, false)
+
+	repo := gittest.New("feat/ABC-1_login", "development")
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-1", "10002", "Login fails on Safari")))
+
+	runRename(renameCmd, nil)
+
+	want := []string{"branch -m feat/ABC-1_login fix/ABC-1_login-fails-on-safari"}
+	if !slices.Equal(repo.Commands, want) || repo.Current != "fix/ABC-1_login-fails-on-safari" {
+		t.Errorf(`runRename() = %q on %q, want match for %q`, repo.Commands, repo.Current, want)
+	}
+}
+
+func TestRunRenameRemote(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useRenameFlags(t, false, true)
+
+	repo := gittest.New("development", "feat/ABC-1_login")
+	repo.Info["feat/ABC-1_login"] = git.BranchInfo{Upstream: "origin/feat/ABC-1_login"}
+	repo.Remote["origin"] = []string{"feat/ABC-1_login"}
+	repo.Tracking["origin"] = []string{"feat/ABC-1_login"}
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-1", "10001", "Login with passkeys")))
+
+	runRename(renameCmd, []string{"feat/ABC-1_login"})
+
+	newName := "feat/ABC-1_login-with-passkeys"
+	want := []string{
+		"branch -m feat/ABC-1_login " + newName,
+		"push -u origin " + newName,
+		"push -d origin feat/ABC-1_login",
+	}
+	if !slices.Equal(repo.Commands, want) || repo.Info[newName].Upstream != "origin/"+newName {
+		t.Errorf(`runRename(feat/ABC-1_login) = %q %+v, want match for %q`, repo.Commands, repo.Info, want)
+	}
+}
+
+func TestRunRenameAll(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useRenameFlags(t, true, true)
+
+	repo := gittest.New("development", "feat/ABC-1_login-page", "chore/ABC-2_old", "hotfix/ABC-3_old", "docs")
+	useFakes(t, repo, newFakeJiraApi(
+		newJiraIssue("ABC-1", "10001", "Login page"),
+		newJiraIssue("ABC-2", "10003", "Update dependencies"),
+		newJiraIssue("ABC-3", "10002", "Crash on start"),
+	))
+
+	runRename(renameCmd, nil)
+
+	// ABC-1 is up to date, ABC-2 keeps its type without a mapping and hotfix/* is protected
+	want := []string{"branch -m chore/ABC-2_old chore/ABC-2_update-dependencies"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runRename(--all) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestRunRenameAllSkipsSeveralIssues(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useRenameFlags(t, true, false)
+
+	repo := gittest.New("development", "feat/ABC-1_ABC-2_combo", "feat/ABC-3_old")
+	useFakes(t, repo, newFakeJiraApi(
+		newJiraIssue("ABC-1", "10001", "Login page"),
+		newJiraIssue("ABC-2", "10001", "Logout page"),
+		newJiraIssue("ABC-3", "10001", "Crash on start"),
+	))
+
+	runRename(renameCmd, nil)
+
+	// the name of ABC-1 alone would drop ABC-2
+	want := []string{"branch -m feat/ABC-3_old feat/ABC-3_crash-on-start"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runRename(--all) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestSelectRenameBranchesSeveralIssues(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useRenameFlags(t, false, false)
+
+	repo := gittest.New("feat/ABC-1_ABC-2_combo", "development")
+
+	if subject, err := selectRenameBranches(repo, nil); err == nil {
+		t.Errorf(`selectRenameBranches() = %+v, want error for a branch of several issues`, subject)
+	}
+}
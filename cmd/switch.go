@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"slices"
+	"strings"
+	"twig/branch"
+	"twig/common"
+	"twig/config"
+	"twig/git"
+	"twig/log"
+)
+
+var (
+	switchCmdName = "switch"
+	switchCmd     = &cobra.Command{
+		Use:         switchCmdName,
+		Short:       "Switches to the branch of a Jira Issue, creates it if there is none",
+		Args:        cobra.ExactArgs(1),
+		Run:         runSwitch,
+		Annotations: map[string]string{annotationRequiresGit: "true"},
+	}
+)
+
+// issueBranch is a branch of an issue, remote-tracking branches are named without the remote.
+type issueBranch struct {
+	name     string
+	location git.BranchLocation
+}
+
+// pickBranch asks which of the branches to switch to, tests replace it.
+var pickBranch = func(issue string, names []string) (int, error) {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Several branches belong to %s", issue),
+		Items: names,
+	}
+
+	i, _, err := prompt.Run()
+	return i, err
+}
+
+func runSwitch(cmd *cobra.Command, args []string) {
+	log.Debug().Println("switch: executing command")
+
+	issue := strings.ToUpper(args[0])
+	if err := validateIssue(issue); err != nil {
+		logCmdFatal(err)
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	b, err := newIssueKeyBranch()
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	remote := config.GetString(config.BranchOrigin)
+
+	branches, err := findIssueBranches(repo, b, remote, issue)
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	// the branch may have been pushed by a teammate since the last fetch
+	if len(branches) == 0 {
+		log.Info().Println(fmt.Sprintf("No branch of %s found, fetching %q", issue, remote))
+
+		if _, err := repo.Fetch(remote, false); err != nil {
+			logCmdFatal(err)
+		}
+
+		if branches, err = findIssueBranches(repo, b, remote, issue); err != nil {
+			logCmdFatal(err)
+		}
+	}
+
+	if len(branches) == 0 {
+		log.Info().Println(fmt.Sprintf("No branch of %s found, creating it", issue))
+		runCreate(createCmd, []string{issue})
+		return
+	}
+
+	selected := branches[0]
+	if len(branches) > 1 {
+		names := make([]string, len(branches))
+		for i, ib := range branches {
+			names[i] = ib.displayName(remote)
+		}
+
+		i, err := pickBranch(issue, names)
+		if err != nil {
+			logCmdFatal(err)
+		}
+
+		selected = branches[i]
+	}
+
+	checkoutCommand, err := common.Checkout(repo, remote, selected.name, selected.location)
+	if err != nil {
+		logCmdFatal(err)
+	}
+
+	if checkoutCommand != "" {
+		log.Info().Println(checkoutCommand)
+	}
+}
+
+// findIssueBranches returns the local branches of the issue followed by the remote-tracking branches
+// of the remote without a local branch of the same name.
+func findIssueBranches(repo git.Repository, b *branch.Branch, remote, issue string) ([]issueBranch, error) {
+	localBranches, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	remoteBranches, err := repo.RemoteBranches(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make([]issueBranch, 0)
+
+	for _, localBranch := range localBranches {
+		if hasIssue(b, localBranch.Name, issue) {
+			branches = append(branches, issueBranch{name: localBranch.Name, location: git.BranchLocal})
+		}
+	}
+
+	for _, remoteBranch := range remoteBranches {
+		name := strings.TrimPrefix(remoteBranch.Name, remote+"/")
+
+		if !repo.HasBranch(name) && hasIssue(b, name, issue) {
+			branches = append(branches, issueBranch{name: name, location: git.BranchRemoteTracking})
+		}
+	}
+
+	return branches, nil
+}
+
+func hasIssue(b *branch.Branch, branchName, issue string) bool {
+	keys, err := b.ExtractIssueKeysFromBranch(branchName)
+	return err == nil && slices.Contains(keys, issue)
+}
+
+func (ib issueBranch) displayName(remote string) string {
+	if ib.location == git.BranchRemoteTracking {
+		return fmt.Sprintf("%s/%s", remote, ib.name)
+	}
+
+	return ib.name
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"twig/git/gittest"
+)
+
+// usePicker picks the branch at index and records the offered names.
+func usePicker(t *testing.T, index int) *[]string {
+	pick := pickBranch
+	t.Cleanup(func() {
+		pickBranch = pick
+	})
+
+	offered := new([]string)
+	pickBranch = func(issue string, names []string) (int, error) {
+		*offered = names
+		return index, nil
+	}
+
+	return offered
+}
+
+func TestRunSwitchLocalBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development", "feat/ABC-1_login", "feat/ABC-12_signup")
+	useFakes(t, repo, newFakeJiraApi())
+	offered := usePicker(t, 0)
+
+	runSwitch(switchCmd, []string{"abc-1"})
+
+	want := []string{"checkout feat/ABC-1_login"}
+	if !slices.Equal(repo.Commands, want) || *offered != nil {
+		t.Errorf(`runSwitch(abc-1) = %q %q, want match for %q without picker`, repo.Commands, *offered, want)
+	}
+}
+
+func TestRunSwitchRemoteOnlyBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development")
+	repo.Remote["origin"] = []string{"fix/ABC-7_broken-logout"}
+	useFakes(t, repo, newFakeJiraApi())
+
+	runSwitch(switchCmd, []string{"ABC-7"})
+
+	want := []string{"fetch origin", "checkout -b fix/ABC-7_broken-logout --track origin/fix/ABC-7_broken-logout"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runSwitch(ABC-7) = %q, want match for %q`, repo.Commands, want)
+	}
+}
+
+func TestRunSwitchPicksBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+
+	repo := gittest.New("development", "feat/ABC-1_login")
+	repo.Tracking["origin"] = []string{"feat/ABC-1_login", "feat/ABC-1_login-v2"}
+	useFakes(t, repo, newFakeJiraApi())
+	offered := usePicker(t, 1)
+
+	runSwitch(switchCmd, []string{"ABC-1"})
+
+	wantOffered := []string{"feat/ABC-1_login", "origin/feat/ABC-1_login-v2"}
+	want := []string{"checkout -b feat/ABC-1_login-v2 --track origin/feat/ABC-1_login-v2"}
+	if !slices.Equal(*offered, wantOffered) || !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runSwitch(ABC-1) = %q %q, want match for %q %q`, *offered, repo.Commands, wantOffered, want)
+	}
+}
+
+func TestRunSwitchCreatesBranch(t *testing.T) {
+	useTestConfig(t, testConfig)
+	useCreateFlags(t, "", false)
+
+	repo := gittest.New("development")
+	useFakes(t, repo, newFakeJiraApi(newJiraIssue("ABC-123", "10001", "Add login page")))
+
+	runSwitch(switchCmd, []string{"ABC-123"})
+
+	want := []string{"fetch origin", "checkout -b feat/ABC-123_add-login-page"}
+	if !slices.Equal(repo.Commands, want) {
+		t.Errorf(`runSwitch(ABC-123) = %q, want match for %q`, repo.Commands, want)
+	}
+}
@@ -4,48 +4,37 @@ import (
 	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
+	"net/http"
 	"os"
 	"strings"
 	"twig/common"
 	"twig/config"
+	"twig/git"
 	"twig/log"
+	"twig/network"
 )
 
 const version = "1.4.3"
 
+// annotationRequiresGit marks commands which run the git binary, unless git.backend is go-git.
+const annotationRequiresGit = "requiresGit"
+
 var (
-	cfgFile string
-	twigCmd = &cobra.Command{
+	cfgFile      string
+	cfgProfile   string
+	cfgOverrides []string
+	twigCmd      = &cobra.Command{
 		DisableAutoGenTag: true,
 		Use:               "twig",
 		Version:           version,
 		Args:              cobra.NoArgs,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			command, _, err := cmd.Find(os.Args[1:])
-			if err != nil {
+			if cmd.Annotations[annotationRequiresGit] != "true" || config.GetString(config.GitBackend) == git.BackendGoGit {
 				return
 			}
 
-			cleanAllName := strings.HasPrefix(
-				command.Name(),
-				fmt.Sprintf("%s %s", cleanCmdName, cleanAllCmdName),
-			)
-			cleanLocalName := strings.HasPrefix(
-				command.Name(),
-				fmt.Sprintf("%s %s", cleanCmdName, cleanLocalCmdName),
-			)
-
-			createName := strings.HasPrefix(
-				command.Name(),
-				createCmdName,
-			)
-
-			matchesCmdName := cleanAllName || cleanLocalName || createName
-
-			if command != nil && matchesCmdName {
-				if !common.HasGit() {
-					logCmdFatal(errors.New("first, Git must be installed! https://git-scm.com/downloads/mac"))
-				}
+			if !common.HasGit() {
+				logCmdFatal(errors.New("first, Git must be installed! https://git-scm.com/downloads/mac"))
 			}
 		},
 	}
@@ -53,7 +42,7 @@ var (
 
 func init() {
 	cobra.OnInitialize(initConfig)
-	
+
 	log.CreateRecorders()
 
 	twigCmd.PersistentFlags().StringVar(
@@ -66,11 +55,33 @@ func init() {
 		),
 	)
 
+	twigCmd.PersistentFlags().StringVar(
+		&cfgProfile,
+		"profile",
+		"",
+		"(optional) Jira profile to use instead of the one selected by the repository",
+	)
+
+	twigCmd.PersistentFlags().StringArrayVarP(
+		&cfgOverrides,
+		"config-value",
+		"c",
+		nil,
+		"(optional) overrides a config value for this run only, e.g. -c branch.default=main",
+	)
+
 	twigCmd.AddCommand(
 		initCmd,
 		createCmd,
+		listCmd,
+		switchCmd,
+		renameCmd,
+		commitCmd,
+		prCmd,
+		hookCmd,
 		cleanCmd,
 		configCmd,
+		doctorCmd,
 	)
 }
 
@@ -81,8 +92,20 @@ func initConfig() {
 		return
 	}
 
+	if init == configMigrateCmd {
+		config.DeferMigrations()
+	}
+
+	// the hooks run inside git commit, a broken config must not abort the commit
+	if init == hookPrepareCommitMsgCmd || init == hookCommitMsgCmd {
+		if hookConfigErr = config.LoadConfig(cfgFile, cfgProfile, cfgOverrides...); hookConfigErr != nil {
+			log.Warn().Println(fmt.Sprintf("hook: %s, the commit message is left unchanged", hookConfigErr.Error()))
+		}
+		return
+	}
+
 	if init != nil && !strings.HasPrefix(init.Name(), InitCmdName) {
-		config.InitConfig(cfgFile)
+		config.InitConfig(cfgFile, cfgProfile, cfgOverrides...)
 	}
 }
 
@@ -92,6 +115,17 @@ func Execute() {
 	}
 }
 
+// openRepository opens the repository of the working directory with the backend from git.backend.
+// Tests replace it to run commands against an in-memory repository.
+var openRepository = func() (git.Repository, error) {
+	return git.Open(config.GetString(config.GitBackend), "")
+}
+
+// newJiraApi is replaced by tests as well.
+var newJiraApi = func() network.JiraApi {
+	return network.NewJiraApi(network.NewHttpClient(&http.Client{}))
+}
+
 func logCmdFatal(err error) {
 	ew := fmt.Errorf("Error: %w", err)
 	log.Fatal().Println(ew)
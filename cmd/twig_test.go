@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequiresGitAnnotation(t *testing.T) {
+	requiresGit := map[string]bool{
+		"create":      true,
+		"list":        true,
+		"switch":      true,
+		"rename":      true,
+		"commit":      true,
+		"pr":          true,
+		"clean all":   true,
+		"clean local": true,
+		"init":        false,
+		"hook":        false,
+		"config":      false,
+		"doctor":      false,
+	}
+
+	for name, want := range requiresGit {
+		command, _, err := twigCmd.Find(strings.Fields(name))
+		if err != nil || command == twigCmd {
+			t.Fatalf(`Find(%q) = %v, want the command`, name, err)
+		}
+
+		if subject := command.Annotations[annotationRequiresGit] == "true"; subject != want {
+			t.Errorf(`twig %s requires git = %t, want %t`, name, subject, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package commit
+
+import (
+    "fmt"
+    "strings"
+)
+
+// SmartCommit holds the Jira smart commit commands of a commit message, which Jira runs when the
+// commit reaches a repository connected to it, e.g. "ABC-123 #time 1h #comment fixed".
+type SmartCommit struct {
+    Comment    string // added as a comment to the issue
+    Time       string // logged work, e.g. 1h 30m
+    Transition string // workflow transition, e.g. resolve or start review
+}
+
+// Format returns the commands for the issue, empty without any.
+func (s SmartCommit) Format(key string) string {
+    commands := make([]string, 0, 3)
+
+    if time := strings.TrimSpace(s.Time); time != "" {
+        commands = append(commands, fmt.Sprintf("#time %s", time))
+    }
+
+    if comment := strings.TrimSpace(s.Comment); comment != "" {
+        commands = append(commands, fmt.Sprintf("#comment %s", comment))
+    }
+
+    // spaces of transition names are written as hyphens
+    if transition := strings.Fields(strings.ToLower(s.Transition)); len(transition) > 0 {
+        commands = append(commands, fmt.Sprintf("#%s", strings.Join(transition, "-")))
+    }
+
+    if len(commands) == 0 {
+        return ""
+    }
+
+    return fmt.Sprintf("%s %s", key, strings.Join(commands, " "))
+}
+
+// Trailer returns a git trailer referencing the issue, e.g. "Refs: ABC-123".
+func Trailer(key string) string {
+    return fmt.Sprintf("Refs: %s", key)
+}
@@ -0,0 +1,22 @@
+package commit
+
+import (
+    "testing"
+)
+
+func TestSmartCommitFormat(t *testing.T) {
+    smart := SmartCommit{Comment: " fixed on Safari ", Time: "1h 30m", Transition: "Start Review"}
+
+    want := "ABC-1 #time 1h 30m #comment fixed on Safari #start-review"
+    subject := smart.Format("ABC-1")
+
+    if subject != want {
+        t.Errorf(`Format("ABC-1") = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestSmartCommitFormatEmpty(t *testing.T) {
+    if subject := (SmartCommit{}).Format("ABC-1"); subject != "" {
+        t.Errorf(`Format("ABC-1") = %q, want empty`, subject)
+    }
+}
@@ -0,0 +1,129 @@
+package commit
+
+import (
+    "fmt"
+    "regexp"
+    "slices"
+    "strings"
+)
+
+// Placeholders of commit.template.
+const (
+    PlaceholderType    = "{type}"
+    PlaceholderKey     = "{key}"
+    PlaceholderMessage = "{message}"
+)
+
+const commentPrefix = "#"
+
+// subjects git writes itself, they are never formatted
+var exemptPrefixes = []string{"fixup! ", "squash! ", "amend! ", "Merge ", "Revert \""}
+
+// Template formats the subject of commit messages, e.g. "{type}({key}): {message}"
+// turns "handle null token" into "fix(ABC-123): handle null token".
+type Template struct {
+    text       string
+    types      []string
+    keyPattern string
+}
+
+func NewTemplate(text string) (*Template, error) {
+    if !strings.Contains(text, PlaceholderMessage) {
+        return nil, fmt.Errorf("template %q has no %s", text, PlaceholderMessage)
+    }
+
+    return &Template{text: text}, nil
+}
+
+// Recognize sets the branch types and the issue key expression of subjects which are formatted already,
+// Apply keeps such subjects even if they name another type or key. Without them every subject is formatted.
+func (t *Template) Recognize(types []string, keyPattern string) {
+    t.types = types
+    t.keyPattern = keyPattern
+}
+
+func (t *Template) String() string {
+    return t.text
+}
+
+func (t *Template) Format(branchType, key, message string) string {
+    return strings.NewReplacer(
+        PlaceholderType, branchType,
+        PlaceholderKey, key,
+        PlaceholderMessage, message,
+    ).Replace(t.text)
+}
+
+// Matches reports whether the subject is formatted with the type and key and has a message.
+func (t *Template) Matches(branchType, key, subject string) bool {
+    return t.match(regexp.QuoteMeta(branchType), regexp.QuoteMeta(key), subject)
+}
+
+// matchesAny reports whether the subject is formatted with any type and key, e.g. of another issue.
+func (t *Template) matchesAny(subject string) bool {
+    if len(t.types) == 0 || t.keyPattern == "" {
+        return false
+    }
+
+    quoted := make([]string, len(t.types))
+    for i, branchType := range t.types {
+        quoted[i] = regexp.QuoteMeta(branchType)
+    }
+
+    return t.match("(?:"+strings.Join(quoted, "|")+")", "(?:"+t.keyPattern+")", subject)
+}
+
+func (t *Template) match(typePattern, keyPattern, subject string) bool {
+    pattern := strings.NewReplacer(
+        regexp.QuoteMeta(PlaceholderType), typePattern,
+        regexp.QuoteMeta(PlaceholderKey), keyPattern,
+        regexp.QuoteMeta(PlaceholderMessage), `\S.*`,
+    ).Replace(regexp.QuoteMeta(t.text))
+
+    return regexp.MustCompile("^" + pattern + "$").MatchString(strings.TrimSpace(subject))
+}
+
+// Apply formats the subject of the message unless it is formatted already, the body and comments are kept.
+// A message without a subject is kept as well, so git still aborts the commit of an empty message.
+func (t *Template) Apply(branchType, key, message string) string {
+    lines := strings.Split(message, "\n")
+
+    i := slices.IndexFunc(lines, isSubjectLine)
+    if i == -1 {
+        return message
+    }
+
+    subject := strings.TrimSpace(lines[i])
+    if IsExempt(subject) || t.matchesAny(subject) {
+        return message
+    }
+
+    lines[i] = t.Format(branchType, key, subject)
+    return strings.Join(lines, "\n")
+}
+
+// Subject returns the first line of the message which is no comment.
+func Subject(message string) string {
+    lines := strings.Split(message, "\n")
+    if i := slices.IndexFunc(lines, isSubjectLine); i != -1 {
+        return strings.TrimSpace(lines[i])
+    }
+
+    return ""
+}
+
+func isSubjectLine(line string) bool {
+    line = strings.TrimSpace(line)
+    return line != "" && !strings.HasPrefix(line, commentPrefix)
+}
+
+// IsExempt reports whether git wrote the subject, e.g. of a merge or a fixup! commit.
+func IsExempt(subject string) bool {
+    for _, prefix := range exemptPrefixes {
+        if strings.HasPrefix(subject, prefix) {
+            return true
+        }
+    }
+
+    return false
+}
@@ -0,0 +1,94 @@
+package commit
+
+import (
+    "testing"
+)
+
+const conventional = "{type}({key}): {message}"
+
+func TestNewTemplateWithoutMessage(t *testing.T) {
+    if subject, err := NewTemplate("{type}({key})"); err == nil {
+        t.Errorf(`NewTemplate("{type}({key})") = %v, want error`, subject)
+    }
+}
+
+func TestTemplateFormat(t *testing.T) {
+    template, _ := NewTemplate(conventional)
+
+    want := "fix(ABC-123): handle null token"
+    subject := template.Format("fix", "ABC-123", "handle null token")
+
+    if subject != want {
+        t.Errorf(`Format() = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestTemplateMatches(t *testing.T) {
+    template, _ := NewTemplate("[{key}] {message}")
+
+    want := map[string]bool{
+        "[ABC-1] handle null token": true,
+        "[ABC-1]":                   false,
+        "[ABC-1] ":                  false,
+        "[ABC-2] handle null token": false,
+        "ABC-1 handle null token":   false,
+    }
+
+    for in, matches := range want {
+        if subject := template.Matches("fix", "ABC-1", in); subject != matches {
+            t.Errorf(`Matches(%q) = %t, want %t`, in, subject, matches)
+        }
+    }
+}
+
+func TestTemplateApply(t *testing.T) {
+    template, _ := NewTemplate(conventional)
+    template.Recognize([]string{"feat", "fix"}, `[A-Z][A-Z0-9]*-\d+`)
+
+    want := map[string]string{
+        "handle null token\n\nbody\n":                   "fix(ABC-1): handle null token\n\nbody\n",
+        "fix(ABC-1): handle null token\n":               "fix(ABC-1): handle null token\n",
+        "feat(XYZ-9): add login\n":                      "feat(XYZ-9): add login\n",
+        "\n# Please enter the commit message\n":         "\n# Please enter the commit message\n",
+        "# Please enter the commit message\n":           "# Please enter the commit message\n",
+        "\n\nhandle null token\n":                       "\n\nfix(ABC-1): handle null token\n",
+        "":                                              "",
+        "Merge branch 'development' into fix/ABC-1_x\n": "Merge branch 'development' into fix/ABC-1_x\n",
+        "fixup! fix(ABC-1): handle null token\n":        "fixup! fix(ABC-1): handle null token\n",
+    }
+
+    for in, out := range want {
+        if subject := template.Apply("fix", "ABC-1", in); subject != out {
+            t.Errorf(`Apply(%q) = %q, want match for %q`, in, subject, out)
+        }
+    }
+}
+
+func TestTemplateApplyRecognizedOnly(t *testing.T) {
+    template, _ := NewTemplate(conventional)
+    template.Recognize([]string{"feat", "fix"}, `(?:ABC)-\d+`)
+
+    want := map[string]string{
+        "feat(ABC-9): add login\n":       "feat(ABC-9): add login\n",
+        "wip(ABC-9): add login\n":        "fix(ABC-1): wip(ABC-9): add login\n",
+        "feat(XYZ-9): add login\n":       "fix(ABC-1): feat(XYZ-9): add login\n",
+        "note(see): handle null token\n": "fix(ABC-1): note(see): handle null token\n",
+    }
+
+    for in, out := range want {
+        if subject := template.Apply("fix", "ABC-1", in); subject != out {
+            t.Errorf(`Apply(%q) = %q, want match for %q`, in, subject, out)
+        }
+    }
+}
+
+func TestSubject(t *testing.T) {
+    in := "\n# comment\n  handle null token  \nbody"
+
+    want := "handle null token"
+    subject := Subject(in)
+
+    if subject != want {
+        t.Errorf(`Subject(in) = %q, want match for %q`, subject, want)
+    }
+}
@@ -3,7 +3,6 @@ package common
 import (
     "errors"
     "fmt"
-    "slices"
     "strings"
     "twig/git"
     "twig/log"
@@ -13,105 +12,95 @@ func HasGit() bool {
     return git.Command(git.Version).Run() == nil
 }
 
-func HasBranch(branchName string) bool {
-    err := git.Command(git.Branch, "--contains", branchName).Run()
-
-    doesExist := err == nil
-    if doesExist {
-        log.Debug().Printf("Branch %q exists locally", branchName)
-    } else {
-        log.Debug().Printf("Branch %q does not exist locally", branchName)
+// FindBranch reports where the branch exists, see git.Repository.FindBranch.
+func FindBranch(repo git.Repository, remote string, branchName string) (git.BranchLocation, error) {
+    location, err := repo.FindBranch(remote, branchName)
+    if err != nil {
+        return location, err
     }
 
-    return doesExist
+    log.Debug().Printf("Branch %q is %s", branchName, location)
+    return location, nil
 }
 
-func Checkout(branchName string, hasBranch bool) (string, error) {
+// Checkout switches to the branch. Remote branches are checked out as branches tracking them
+// and missing branches are created from HEAD.
+func Checkout(repo git.Repository, remote string, branchName string, location git.BranchLocation) (string, error) {
     log.Info().Println(fmt.Sprintf("Checkout to %q", branchName))
 
-    args := []string{branchName}
-
-    if !hasBranch {
-        log.Debug().Printf(fmt.Sprintf("Branch %q is new, adding '-b' flag", branchName))
-        args = slices.Insert(args, 0, "-b")
-    }
-
-    out, err := git.Command(git.Checkout, args...).CombinedOutput()
-    if err != nil {
-        return string(out), err
+    switch location {
+    case git.BranchLocal:
+        return repo.Checkout(branchName, false)
+    case git.BranchRemoteOnly:
+        log.Debug().Printf("Branch %q exists only on %q, fetching it", branchName, remote)
+
+        if out, err := repo.Fetch(remote, false); err != nil {
+            return out, err
+        }
+        fallthrough
+    case git.BranchRemoteTracking:
+        log.Debug().Printf("Branch %q is new, tracking '%s/%s'", branchName, remote, branchName)
+        return repo.CheckoutTracking(remote, branchName)
+    default:
+        log.Debug().Printf("Branch %q is new, adding '-b' flag", branchName)
+        return repo.Checkout(branchName, true)
     }
-
-    return string(out), nil
 }
 
-func BranchStatus() error {
+func BranchStatus(repo git.Repository) error {
     log.Info().Println("Check branch status")
 
-    out, err := git.Command(git.Status, "-s").CombinedOutput()
+    isClean, err := repo.IsClean()
     if err != nil {
         return err
     }
 
-    if outputSize := len(string(out)); outputSize > 1 {
+    if !isClean {
         return errors.New("current branch has uncommitted changes")
     }
 
     return nil
 }
 
-func GetLocalBranches() (string, error) {
+func GetLocalBranches(repo git.Repository) ([]git.BranchInfo, error) {
     log.Info().Println("Get local branches")
 
-    out, err := git.Command(git.Branch).CombinedOutput()
+    return repo.Branches()
+}
+
+func IsMerged(repo git.Repository, branchName string, into string) bool {
+    isMerged, err := repo.IsAncestor(branchName, into)
     if err != nil {
-        return "", err
+        log.Debug().Println(err.Error())
     }
 
-    return string(out), nil
+    log.Debug().Printf("Branch %q merged into %q: %t", branchName, into, isMerged)
+
+    return isMerged
 }
 
-func ExecuteFetchPrune() (string, error) {
+func ExecuteFetchPrune(repo git.Repository, remote string) (string, error) {
     log.Info().Println("Run fetch and prune")
 
-    out, err := git.Command(git.Fetch, "-p").CombinedOutput()
-    if err != nil {
-        return "", err
-    }
-
-    return string(out), nil
+    return repo.Fetch(remote, true)
 }
 
-func DeleteLocalBranch(branchName string) (string, error) {
+func DeleteLocalBranch(repo git.Repository, branchName string) (string, error) {
     log.Info().Println(fmt.Sprintf("Delete local branch %q", branchName))
 
-    out, err := git.Command(git.Branch, "-D", branchName).CombinedOutput()
-    if err != nil {
-        return string(out), err
-    }
-
-    return string(out), nil
+    return repo.DeleteBranch(branchName)
 }
 
-func DeleteRemoteBranch(remote string, branchName string) (string, error) {
+func DeleteRemoteBranch(repo git.Repository, remote string, branchName string) (string, error) {
     log.Info().Println(fmt.Sprintf("Delete remote branch '%s/%s'", remote, branchName))
 
-    out, err := git.Command(git.Push, "-d", remote, branchName).CombinedOutput()
-    if err != nil {
-        return string(out), err
-    }
-
-    return string(out), nil
+    return repo.DeleteRemoteBranch(remote, branchName)
 }
 
-func PushToRemote(branchName string, remote string) (string, error) {
+func PushToRemote(repo git.Repository, branchName string, remote string) (string, error) {
     log.Info().Println(fmt.Sprintf("Push branch to remote '%s/%s'", remote, branchName))
 
-    out, err := git.Command(git.Push, "-u", remote, branchName).CombinedOutput()
-    if err != nil {
-        return string(out), err
-    }
-
-    return string(out), nil
+    return repo.Push(remote, branchName)
 }
 
 func ExtractUsernameFromEmail(email string) (string, error) {
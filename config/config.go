@@ -8,47 +8,29 @@ import (
     "github.com/spf13/viper"
     "os"
     "path/filepath"
+    "strings"
     "twig/log"
 )
 
 //go:embed twig.toml
 var embededCfg []byte
 
-type Token int
-
-const (
-    Unspecified Token = iota - 1
-    Project
-    ProjectHost
-    ProjectAuth
-    ProjectEmail
-    ProjectToken
-
-    Branch
-    BranchDefault
-    BranchOrigin
-    BranchExclude
-
-    Mapping
-    MappingBuild
-    MappingChore
-    MappingCi
-    MappingDocs
-    MappingFeat
-    MappingFix
-    MappingPref
-    MappingRefactor
-    MappingRevert
-    MappingStyle
-    MappingTest
-)
-
 type Config struct {
-    manager *viper.Viper
-    name    string
-    ext     string
-    path    string
-    homeDir string
+    manager   *viper.Viper
+    name      string
+    ext       string
+    path      string
+    localName string
+    homeDir   string
+
+    file       string   // custom config file from --config
+    profile    string   // profile from --profile
+    active     string   // profile applied on top of [project]
+    overrides  []string // key=value pairs from flags
+    target     Layer    // layer written by Set* functions
+    isDeferred bool     // migrations are not written while loading
+    layers     []Layer
+    sources    map[string]Layer
 }
 
 var c *Config
@@ -60,10 +42,12 @@ func init() {
 func New() *Config {
     c := new(Config)
 
-    c.manager = viper.GetViper()
+    c.manager = viper.New()
     c.name = "twig"
     c.ext = "toml"
     c.path = "/.config/twig"
+    c.localName = ".twig"
+    c.sources = make(map[string]Layer)
 
     dir, err := homedir.Dir()
     if err != nil {
@@ -75,15 +59,31 @@ func New() *Config {
     return c
 }
 
+// Set parses the input according to the registered key and writes it, see Key.Parse.
+func Set(token Token, input string) error {
+    return c.Set(token, input)
+}
+
+func (c *Config) Set(token Token, input string) error {
+    key := Lookup(token)
+    if key.Token == Unspecified {
+        return errors.New("unexpected token from input")
+    }
+
+    value, err := key.Parse(input)
+    if err != nil {
+        return fmt.Errorf("%s: %w", key.Name, err)
+    }
+
+    return c.write(key.Name, value)
+}
+
 func SetString(token Token, value string) error {
     return c.SetString(token, value)
 }
 
 func (c *Config) SetString(token Token, value string) error {
-    key := FromToken(token)
-    c.manager.Set(key, value)
-
-    return c.overrideConfig()
+    return c.write(FromToken(token), value)
 }
 
 func SetStringArray(token Token, value []string) error {
@@ -91,10 +91,7 @@ func SetStringArray(token Token, value []string) error {
 }
 
 func (c *Config) SetStringArray(token Token, value []string) error {
-    key := FromToken(token)
-    c.manager.Set(key, value)
-
-    return c.overrideConfig()
+    return c.write(FromToken(token), value)
 }
 
 func GetString(token Token) string {
@@ -112,6 +109,15 @@ func GetStringArray(token Token) []string {
 
 func (c *Config) GetStringArray(token Token) []string {
     key := FromToken(token)
+
+    // environment variables hold lists as comma separated values
+    if value, ok := c.manager.Get(key).(string); ok {
+        if value == "" {
+            return []string{}
+        }
+        return splitValues(value)
+    }
+
     return c.manager.GetStringSlice(key)
 }
 
@@ -120,55 +126,81 @@ func GetStringMap(token Token) map[string][]string {
 }
 
 func (c *Config) GetStringMap(token Token) map[string][]string {
-    key := FromToken(token)
-    return c.manager.GetStringMapStringSlice(key)
+    result := make(map[string][]string)
+    prefix := FromToken(token) + "."
+
+    for _, t := range Tokens() {
+        key := FromToken(t)
+        if !strings.HasPrefix(key, prefix) || !c.manager.IsSet(key) {
+            continue
+        }
+
+        result[strings.TrimPrefix(key, prefix)] = c.GetStringArray(t)
+    }
+
+    return result
 }
 
 func GetAllSnapshot() (*Settings, error) {
     return c.GetAllSnapshot()
 }
 
+// GetAllSnapshot decodes the merged config into Settings. Registered keys missing from
+// Settings are ignored here, unknown keys are reported by Validate.
 func (c *Config) GetAllSnapshot() (*Settings, error) {
     var cfg *Settings
 
-    if err := c.manager.UnmarshalExact(&cfg); err != nil {
+    if err := c.manager.Unmarshal(&cfg); err != nil {
         return nil, fmt.Errorf("failed to get config snapshot: %w", err)
     }
 
     return cfg, nil
 }
 
-func InitConfig(file string) {
-    if file != "" {
-        log.Debug().Println("Using custom config")
-
-        c.manager.SetConfigFile(file)
-        c.manager.SetConfigType(c.ext)
-    } else {
-        log.Debug().Println("Using default config")
-
-        c.manager.AddConfigPath(c.homeDir + c.path)
-        c.manager.SetConfigName(c.name)
-        c.manager.SetConfigType(c.ext)
+// InitConfig loads every config layer, see Layers for the order.
+// The file replaces the global config, the profile is used instead of automatic selection
+// and overrides are key=value pairs applied on top of all layers.
+func InitConfig(file string, profile string, overrides ...string) {
+    if err := LoadConfig(file, profile, overrides...); err != nil {
+        log.Fatal().Println(err)
     }
+}
 
-    if err := c.manager.ReadInConfig(); err != nil {
-        log.Fatal().Println(err)
+// LoadConfig is InitConfig returning the error, for callers which must not exit, e.g. git hooks.
+func LoadConfig(file string, profile string, overrides ...string) error {
+    c.file = file
+    c.profile = profile
+    c.overrides = overrides
+
+    if err := c.load(); err != nil {
+        return err
     }
 
     log.Debug().Println("Config loaded")
+    return nil
 }
 
-func IsConfigExist() error {
+// Reload loads the layers again, e.g. after the working directory moved to another repository.
+func Reload() error {
+    return c.load()
+}
+
+// SetHomeDir moves the global config into dir and returns the previous home directory, e.g. for tests.
+// The config is not reloaded.
+func SetHomeDir(dir string) string {
+    previous := c.homeDir
+    c.homeDir = dir
+
+    return previous
+}
+
+// IsConfigExist reports whether the global config file exists.
+func IsConfigExist() bool {
     fileName := fmt.Sprintf("%s.%s", c.name, c.ext)
     path := filepath.Join(c.homeDir, c.path, fileName)
 
     _, err := os.Stat(path)
-
-    if err == nil || os.IsExist(err) {
-        return errors.New("config exist, abort")
-    }
-    return nil
+    return err == nil
 }
 
 func CreateConfigDir() error {
@@ -211,108 +243,3 @@ func GetDefaultConfigPath() string {
         c.ext,
     )
 }
-
-func (c *Config) overrideConfig() error {
-    if err := c.manager.WriteConfig(); err != nil {
-        return fmt.Errorf("failed to save config: %w", err)
-    }
-    return nil
-}
-
-func FromToken(token Token) string {
-    switch token {
-    case Project:
-        return "project"
-    case ProjectHost:
-        return "project.host"
-    case ProjectAuth:
-        return "project.auth"
-    case ProjectEmail:
-        return "project.email"
-    case ProjectToken:
-        return "project.token"
-    case Branch:
-        return "branch"
-    case BranchDefault:
-        return "branch.default"
-    case BranchOrigin:
-        return "branch.origin"
-    case BranchExclude:
-        return "branch.exclude"
-    case Mapping:
-        return "mapping"
-    case MappingBuild:
-        return "mapping.build"
-    case MappingChore:
-        return "mapping.chore"
-    case MappingCi:
-        return "mapping.ci"
-    case MappingDocs:
-        return "mapping.docs"
-    case MappingFeat:
-        return "mapping.feat"
-    case MappingFix:
-        return "mapping.fix"
-    case MappingPref:
-        return "mapping.pref"
-    case MappingRefactor:
-        return "mapping.refactor"
-    case MappingRevert:
-        return "mapping.revert"
-    case MappingStyle:
-        return "mapping.style"
-    case MappingTest:
-        return "mapping.test"
-    default:
-        return ""
-    }
-}
-
-func FromInput(token string) (Token, error) {
-    switch token {
-    case "project":
-        return Project, nil
-    case "project.host":
-        return ProjectHost, nil
-    case "project.auth":
-        return ProjectAuth, nil
-    case "project.email":
-        return ProjectEmail, nil
-    case "project.token":
-        return ProjectToken, nil
-    case "branch":
-        return Branch, nil
-    case "branch.default":
-        return BranchDefault, nil
-    case "branch.origin":
-        return BranchOrigin, nil
-    case "branch.exclude":
-        return BranchExclude, nil
-    case "mapping":
-        return Mapping, nil
-    case "mapping.build":
-        return MappingBuild, nil
-    case "mapping.chore":
-        return MappingChore, nil
-    case "mapping.ci":
-        return MappingCi, nil
-    case "mapping.docs":
-        return MappingDocs, nil
-    case "mapping.feat":
-        return MappingFeat, nil
-    case "mapping.fix":
-        return MappingFix, nil
-    case "mapping.pref":
-        return MappingPref, nil
-    case "mapping.refactor":
-        return MappingRefactor, nil
-    case "mapping.revert":
-        return MappingRevert, nil
-    case "mapping.style":
-        return MappingStyle, nil
-    case "mapping.test":
-        return MappingTest, nil
-    default:
-        return Unspecified, errors.New("unexpected token from input")
-    }
-}
@@ -0,0 +1,432 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "github.com/spf13/viper"
+    "io"
+    "maps"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+    "twig/git"
+    "twig/log"
+)
+
+const (
+    LayerDefault = "default" // defaults of the key registry
+    LayerGlobal  = "global"  // ~/.config/twig/twig.toml or --config
+    LayerXdg     = "xdg"     // $XDG_CONFIG_HOME/twig/twig.toml
+    LayerRepo    = "repo"    // .twig.toml in the repository root
+    LayerProfile = "profile" // [profiles.<name>] applied on top of [project]
+    LayerEnv     = "env"     // TWIG_* environment variables
+    LayerFlag    = "flag"    // -c key=value
+)
+
+const envPrefix = "TWIG"
+
+// layerOrder lists layers from the lowest to the highest priority.
+var layerOrder = []string{LayerDefault, LayerGlobal, LayerXdg, LayerRepo, LayerProfile, LayerEnv, LayerFlag}
+
+type Layer struct {
+    Name string
+    Path string
+}
+
+func (l Layer) String() string {
+    if l.Path == "" {
+        return l.Name
+    }
+
+    return fmt.Sprintf("%s:%s", l.Name, l.Path)
+}
+
+// Layers returns the loaded layers from the lowest to the highest priority.
+func Layers() []Layer {
+    return slices.Clone(c.layers)
+}
+
+// Source returns the layer which provided the current value of the token.
+func Source(token Token) Layer {
+    return c.Source(token)
+}
+
+func (c *Config) Source(token Token) Layer {
+    return c.SourceOf(FromToken(token))
+}
+
+// SourceOf returns the layer which provided the current value of the key, e.g. "branch.default".
+func SourceOf(key string) Layer {
+    return c.SourceOf(key)
+}
+
+func (c *Config) SourceOf(key string) Layer {
+    layer, ok := c.sources[key]
+    if !ok {
+        return Layer{Name: LayerDefault}
+    }
+
+    return layer
+}
+
+// UseRepoLayer makes Set* functions write into the repository's .twig.toml instead of the global config.
+func UseRepoLayer() error {
+    path := c.repoPath()
+    if path == "" {
+        return errors.New("not inside a git repository")
+    }
+
+    c.target = Layer{Name: LayerRepo, Path: path}
+    return nil
+}
+
+func (c *Config) load() error {
+    c.manager = viper.New()
+    c.manager.SetConfigType(c.ext)
+    c.layers = make([]Layer, 0, len(layerOrder))
+    c.sources = make(map[string]Layer)
+    c.active = ""
+
+    if err := c.mergeDefaults(); err != nil {
+        return err
+    }
+
+    global := c.globalPath()
+    if c.file != "" {
+        global = c.file
+    }
+
+    if err := c.mergeFile(Layer{Name: LayerGlobal, Path: global}, c.file != ""); err != nil {
+        return err
+    }
+
+    if xdg := c.xdgPath(); xdg != "" && xdg != global {
+        if err := c.mergeFile(Layer{Name: LayerXdg, Path: xdg}, false); err != nil {
+            return err
+        }
+    }
+
+    if repo := c.repoPath(); repo != "" {
+        if err := c.mergeFile(Layer{Name: LayerRepo, Path: repo}, false); err != nil {
+            return err
+        }
+    }
+
+    if err := c.bindEnv(); err != nil {
+        return err
+    }
+
+    if err := c.applyProfile(); err != nil {
+        return err
+    }
+
+    if err := c.applyOverrides(); err != nil {
+        return err
+    }
+
+    c.target = Layer{Name: LayerGlobal, Path: global}
+    return nil
+}
+
+func (c *Config) mergeFile(layer Layer, required bool) error {
+    file, err := os.Open(layer.Path)
+    if err != nil {
+        if !required && errors.Is(err, os.ErrNotExist) {
+            log.Debug().Println(fmt.Sprintf("Skip %s config, %q does not exist", layer.Name, layer.Path))
+            return nil
+        }
+
+        return fmt.Errorf("failed to read %s config: %w", layer.Name, err)
+    }
+
+    defer func() {
+        if err := file.Close(); err != nil {
+            log.Error().Println(fmt.Errorf("config: %w", err))
+        }
+    }()
+
+    return c.merge(layer, file)
+}
+
+func (c *Config) merge(layer Layer, in io.Reader) error {
+    v := viper.New()
+    v.SetConfigType(c.ext)
+
+    if err := v.ReadConfig(in); err != nil {
+        return fmt.Errorf("failed to parse %s config: %w", layer, err)
+    }
+
+    settings := v.AllSettings()
+    isChanged := false
+
+    if m := migrateSettings(layer, settings); m.From != m.To {
+        c.autoMigrate(&m, settings)
+        isChanged = true
+    }
+
+    if layer.Name == LayerRepo {
+        droppedKeys := dropRepoKeys(layer, settings)
+        droppedSecrets := dropRepoSecrets(layer, settings)
+        isChanged = isChanged || droppedKeys || droppedSecrets
+    }
+
+    if isChanged {
+        v = viper.New()
+        v.SetConfigType(c.ext)
+        if err := v.MergeConfigMap(settings); err != nil {
+            return fmt.Errorf("failed to migrate %s config: %w", layer, err)
+        }
+    }
+
+    return c.mergeViper(layer, v)
+}
+
+// repoSections are the sections a repository may set. Connections and credentials stay with the user,
+// a cloned repository could otherwise send them to a host it controls.
+var repoSections = []Token{Branch, Mapping, Clean, Issue, Commit, Workspace}
+
+// IsRepoKey reports whether the key may be set in .twig.toml of a repository.
+func IsRepoKey(key string) bool {
+    section, _, _ := strings.Cut(key, ".")
+
+    return slices.ContainsFunc(repoSections, func(token Token) bool { return FromToken(token) == section })
+}
+
+// dropRepoKeys removes the sections a repository may not set.
+func dropRepoKeys(layer Layer, settings map[string]any) bool {
+    dropped := false
+
+    for _, name := range slices.Sorted(maps.Keys(settings)) {
+        if name == versionKey || IsRepoKey(name) {
+            continue
+        }
+
+        delete(settings, name)
+        log.Warn().Println(fmt.Sprintf("Ignore %q of %s config, a repository may only set %s", name, layer, repoSectionNames()))
+        dropped = true
+    }
+
+    return dropped
+}
+
+func repoSectionNames() string {
+    names := make([]string, len(repoSections))
+    for i, token := range repoSections {
+        names[i] = FromToken(token)
+    }
+
+    return strings.Join(names, ", ")
+}
+
+// dropRepoSecrets removes tokens from the settings of a repository. A cloned repository must not
+// make twig run its helper: commands or read files through secret references.
+func dropRepoSecrets(layer Layer, settings map[string]any) bool {
+    dropped := make([]string, 0)
+
+    for _, key := range Keys() {
+        if key.Secret && deleteKey(settings, strings.Split(key.Name, ".")) {
+            dropped = append(dropped, key.Name)
+        }
+    }
+
+    if profiles, ok := settings[profilesKey].(map[string]any); ok {
+        for _, name := range slices.Sorted(maps.Keys(profiles)) {
+            if deleteKey(profiles, []string{name, "token"}) {
+                dropped = append(dropped, fmt.Sprintf("%s.%s.token", profilesKey, name))
+            }
+        }
+    }
+
+    for _, key := range dropped {
+        log.Warn().Println(fmt.Sprintf("Ignore %q of %s config, tokens are only read from the global config, environment variables and flags", key, layer))
+    }
+
+    return len(dropped) > 0
+}
+
+// mergeDefaults merges the defaults of every registered key as the lowest layer.
+func (c *Config) mergeDefaults() error {
+    v := viper.New()
+    for _, key := range Keys() {
+        v.Set(key.Name, key.Default)
+    }
+
+    return c.mergeViper(Layer{Name: LayerDefault}, v)
+}
+
+func (c *Config) mergeViper(layer Layer, v *viper.Viper) error {
+    if err := c.manager.MergeConfigMap(v.AllSettings()); err != nil {
+        return fmt.Errorf("failed to merge %s config: %w", layer, err)
+    }
+
+    for _, key := range v.AllKeys() {
+        c.sources[key] = layer
+    }
+
+    c.layers = append(c.layers, layer)
+    log.Debug().Println(fmt.Sprintf("Using %s config", layer))
+
+    return nil
+}
+
+// EnvName returns the environment variable which overrides the token, e.g. TWIG_BRANCH_DEFAULT.
+func EnvName(token Token) string {
+    key := strings.ReplaceAll(FromToken(token), ".", "_")
+    return fmt.Sprintf("%s_%s", envPrefix, strings.ToUpper(key))
+}
+
+func (c *Config) bindEnv() error {
+    c.manager.SetEnvPrefix(envPrefix)
+    c.manager.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+    isUsed := false
+
+    for _, token := range Tokens() {
+        if !strings.Contains(FromToken(token), ".") {
+            continue // sections can't be set from a single variable
+        }
+
+        if err := c.manager.BindEnv(FromToken(token)); err != nil {
+            return fmt.Errorf("failed to bind %q: %w", EnvName(token), err)
+        }
+
+        if _, ok := os.LookupEnv(EnvName(token)); ok {
+            c.sources[FromToken(token)] = Layer{Name: LayerEnv, Path: EnvName(token)}
+            isUsed = true
+        }
+    }
+
+    if isUsed {
+        c.layers = append(c.layers, Layer{Name: LayerEnv})
+    }
+
+    return nil
+}
+
+func (c *Config) applyOverrides() error {
+    if len(c.overrides) == 0 {
+        return nil
+    }
+
+    layer := Layer{Name: LayerFlag}
+
+    for _, override := range c.overrides {
+        key, value, ok := strings.Cut(override, "=")
+        if !ok {
+            return fmt.Errorf("override %q must be in key=value format", override)
+        }
+
+        token, err := FromInput(strings.TrimSpace(key))
+        if err != nil {
+            return fmt.Errorf("override %q: %w", override, err)
+        }
+
+        key = FromToken(token)
+        if IsArray(token) {
+            c.manager.Set(key, splitValues(value))
+        } else {
+            c.manager.Set(key, strings.TrimSpace(value))
+        }
+
+        c.sources[key] = layer
+    }
+
+    c.layers = append(c.layers, layer)
+    return nil
+}
+
+// write stores the key in the target layer only, so values of other layers never leak into it.
+func (c *Config) write(key string, value any) error {
+    if c.target.Name == LayerRepo && !IsRepoKey(key) {
+        return fmt.Errorf("%q can not be set in %s config, a repository may only set %s", key, c.target.Name, repoSectionNames())
+    }
+
+    v, err := c.readTarget()
+    if err != nil {
+        return err
+    }
+
+    v.Set(key, value)
+
+    if err = os.MkdirAll(filepath.Dir(c.target.Path), 0o755); err != nil {
+        return fmt.Errorf("failed to save config: %w", err)
+    }
+
+    if err = v.WriteConfigAs(c.target.Path); err != nil {
+        return fmt.Errorf("failed to save config: %w", err)
+    }
+
+    // the value of the higher layer stays in effect for this run as well
+    if source, ok := c.sources[key]; ok && layerIndex(source.Name) > layerIndex(c.target.Name) {
+        log.Warn().Println(fmt.Sprintf("%q is overridden by %s config", key, source))
+        return nil
+    }
+
+    c.sources[key] = c.target
+    c.manager.Set(key, value)
+    return nil
+}
+
+func (c *Config) targetLayer() Layer {
+    if c.target.Path == "" {
+        c.target = Layer{Name: LayerGlobal, Path: c.globalPath()}
+    }
+
+    return c.target
+}
+
+func (c *Config) globalPath() string {
+    return filepath.Join(c.homeDir, c.path, fmt.Sprintf("%s.%s", c.name, c.ext))
+}
+
+func (c *Config) xdgPath() string {
+    dir := os.Getenv("XDG_CONFIG_HOME")
+    if dir == "" {
+        return ""
+    }
+
+    return filepath.Join(dir, c.name, fmt.Sprintf("%s.%s", c.name, c.ext))
+}
+
+func (c *Config) repoPath() string {
+    root := c.gitRoot()
+    if root == "" {
+        return ""
+    }
+
+    return filepath.Join(root, fmt.Sprintf("%s.%s", c.localName, c.ext))
+}
+
+// repository opens the repository of the working directory with the backend of git.backend.
+// While loading, only the layers merged so far select the backend.
+func (c *Config) repository() (git.Repository, error) {
+    return git.Open(c.manager.GetString(FromToken(GitBackend)), "")
+}
+
+func (c *Config) gitRoot() string {
+    repo, err := c.repository()
+    if err != nil {
+        return ""
+    }
+
+    root, err := repo.Root()
+    if err != nil {
+        return ""
+    }
+
+    return root
+}
+
+func layerIndex(name string) int {
+    return slices.Index(layerOrder, name)
+}
+
+func splitValues(value string) []string {
+    values := strings.Split(value, ",")
+    for i := range values {
+        values[i] = strings.TrimSpace(values[i])
+    }
+
+    return values
+}
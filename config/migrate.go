@@ -0,0 +1,213 @@
+package config
+
+import (
+    "fmt"
+    "github.com/spf13/viper"
+    "os"
+    "slices"
+    "strconv"
+    "strings"
+    "twig/log"
+)
+
+// CurrentVersion is the config schema version written by this twig, files without a version are 1.
+const CurrentVersion = 2
+
+const versionKey = "version"
+
+type migration struct {
+    version     int // version the migration upgrades to
+    description string
+    apply       func(settings map[string]any) bool
+}
+
+// migrations upgrade config files one version at a time, a new schema change is a new entry.
+var migrations = []migration{
+    {version: 2, description: "rename mapping.pref to mapping.perf", apply: renameKey("mapping.pref", "mapping.perf")},
+}
+
+// Migration describes the upgrade of a single config file.
+type Migration struct {
+    Layer   Layer
+    From    int
+    To      int
+    Changes []string
+    Backup  string
+}
+
+// DeferMigrations keeps config files untouched while loading, old files are still upgraded in memory.
+// Migrate writes them afterwards.
+func DeferMigrations() {
+    c.isDeferred = true
+}
+
+// Migrate upgrades every loaded config file to CurrentVersion, keeping a backup of the original.
+// Nothing is written with dryRun, the returned migrations only describe the changes.
+func Migrate(dryRun bool) ([]Migration, error) {
+    return c.Migrate(dryRun)
+}
+
+func (c *Config) Migrate(dryRun bool) ([]Migration, error) {
+    result := make([]Migration, 0)
+
+    for _, layer := range c.layers {
+        if layer.Path == "" || !slices.Contains([]string{LayerGlobal, LayerXdg, LayerRepo}, layer.Name) {
+            continue
+        }
+
+        v := viper.New()
+        v.SetConfigType(c.ext)
+        v.SetConfigFile(layer.Path)
+
+        if err := v.ReadInConfig(); err != nil {
+            return nil, fmt.Errorf("failed to read %s config: %w", layer.Name, err)
+        }
+
+        settings := v.AllSettings()
+        m := migrateSettings(layer, settings)
+        if m.From == m.To {
+            continue
+        }
+
+        if !dryRun {
+            if err := c.persistMigration(&m, settings); err != nil {
+                return nil, err
+            }
+        }
+
+        result = append(result, m)
+    }
+
+    return result, nil
+}
+
+// migrateSettings applies every migration above the version of the settings in place.
+func migrateSettings(layer Layer, settings map[string]any) Migration {
+    m := Migration{Layer: layer, From: settingsVersion(settings), Changes: make([]string, 0)}
+    m.To = m.From
+
+    if m.From > CurrentVersion {
+        log.Warn().Println(fmt.Sprintf("%s config has version %d, newer than %d of this twig, update twig", layer, m.From, CurrentVersion))
+        return m
+    }
+
+    for _, step := range migrations {
+        if step.version <= m.From {
+            continue
+        }
+
+        if step.apply(settings) {
+            m.Changes = append(m.Changes, step.description)
+        }
+        m.To = step.version
+    }
+
+    settings[versionKey] = m.To
+    return m
+}
+
+// autoMigrate writes a migration found while loading. Files are only rewritten when a key changed and
+// never in a repository, where it would leave the worktree dirty. A file which can't be written,
+// e.g. a read-only mount, is still used with the migrated values.
+func (c *Config) autoMigrate(m *Migration, settings map[string]any) {
+    switch {
+    case len(m.Changes) == 0:
+        return
+    case c.isDeferred:
+        log.Debug().Println(fmt.Sprintf("%s config has version %d, run \"twig config migrate\"", m.Layer, m.From))
+    case m.Layer.Name == LayerRepo:
+        log.Warn().Println(fmt.Sprintf("%s config has version %d, run \"twig config migrate\" to upgrade it", m.Layer, m.From))
+    default:
+        if err := c.persistMigration(m, settings); err != nil {
+            log.Warn().Println(fmt.Sprintf("%s, using the migrated values for this run", err.Error()))
+        }
+    }
+}
+
+// persistMigration writes the migrated settings, the original file is kept as <file>.v<from>.bak.
+func (c *Config) persistMigration(m *Migration, settings map[string]any) error {
+    original, err := os.ReadFile(m.Layer.Path)
+    if err != nil {
+        return fmt.Errorf("failed to back up %s config: %w", m.Layer.Name, err)
+    }
+
+    m.Backup = fmt.Sprintf("%s.v%d.bak", m.Layer.Path, m.From)
+    if err = os.WriteFile(m.Backup, original, 0o600); err != nil {
+        return fmt.Errorf("failed to back up %s config: %w", m.Layer.Name, err)
+    }
+
+    v := viper.New()
+    v.SetConfigType(c.ext)
+    if err = v.MergeConfigMap(settings); err != nil {
+        return fmt.Errorf("failed to migrate %s config: %w", m.Layer.Name, err)
+    }
+
+    if err = v.WriteConfigAs(m.Layer.Path); err != nil {
+        return fmt.Errorf("failed to migrate %s config: %w", m.Layer.Name, err)
+    }
+
+    log.Info().Println(fmt.Sprintf("Migrated %s config from version %d to %d, backup in %q", m.Layer, m.From, m.To, m.Backup))
+    return nil
+}
+
+func settingsVersion(settings map[string]any) int {
+    switch value := settings[versionKey].(type) {
+    case int:
+        return value
+    case int64:
+        return int(value)
+    case string:
+        if version, err := strconv.Atoi(value); err == nil {
+            return version
+        }
+    }
+
+    return 1
+}
+
+// renameKey moves a dotted key, an existing value of the new key wins.
+func renameKey(from, to string) func(settings map[string]any) bool {
+    return func(settings map[string]any) bool {
+        value, ok := nestedValue(settings, strings.Split(from, "."))
+        if !ok {
+            return false
+        }
+
+        deleteKey(settings, strings.Split(from, "."))
+
+        if _, exists := nestedValue(settings, strings.Split(to, ".")); !exists {
+            setNestedValue(settings, strings.Split(to, "."), value)
+        }
+
+        return true
+    }
+}
+
+func nestedValue(settings map[string]any, path []string) (any, bool) {
+    value, ok := settings[path[0]]
+    if !ok || len(path) == 1 {
+        return value, ok
+    }
+
+    section, ok := value.(map[string]any)
+    if !ok {
+        return nil, false
+    }
+
+    return nestedValue(section, path[1:])
+}
+
+func setNestedValue(settings map[string]any, path []string, value any) {
+    if len(path) == 1 {
+        settings[path[0]] = value
+        return
+    }
+
+    section, ok := settings[path[0]].(map[string]any)
+    if !ok {
+        section = make(map[string]any)
+        settings[path[0]] = section
+    }
+
+    setNestedValue(section, path[1:], value)
+}
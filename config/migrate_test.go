@@ -0,0 +1,169 @@
+package config
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestMigrateSettingsRenamesPref(t *testing.T) {
+    settings := map[string]any{
+        "mapping": map[string]any{"pref": []any{"10010"}},
+    }
+
+    subject := migrateSettings(Layer{Name: LayerGlobal}, settings)
+
+    if subject.From != 1 || subject.To != CurrentVersion || len(subject.Changes) != 1 {
+        t.Errorf(`migrateSettings() = %+v, want one change from 1 to %d`, subject, CurrentVersion)
+    }
+
+    mapping := settings["mapping"].(map[string]any)
+    if _, ok := mapping["pref"]; ok {
+        t.Errorf(`migrateSettings() kept "mapping.pref"`)
+    }
+
+    if _, ok := mapping["perf"]; !ok {
+        t.Errorf(`migrateSettings() = %v, want "mapping.perf"`, mapping)
+    }
+}
+
+func TestMigrateSettingsKeepsExistingPerf(t *testing.T) {
+    settings := map[string]any{
+        "mapping": map[string]any{"pref": []any{"1"}, "perf": []any{"2"}},
+    }
+
+    migrateSettings(Layer{Name: LayerGlobal}, settings)
+
+    subject, _ := nestedValue(settings, []string{"mapping", "perf"})
+    if values := subject.([]any); len(values) != 1 || values[0] != "2" {
+        t.Errorf(`mapping.perf = %v, want match for ["2"]`, subject)
+    }
+}
+
+func TestMigrateSettingsNewerVersion(t *testing.T) {
+    settings := map[string]any{
+        versionKey: int64(CurrentVersion + 1),
+        "mapping":  map[string]any{"pref": []any{"1"}},
+    }
+
+    subject := migrateSettings(Layer{Name: LayerGlobal}, settings)
+
+    if subject.From != subject.To || len(subject.Changes) != 0 {
+        t.Errorf(`migrateSettings() = %+v, want no changes`, subject)
+    }
+}
+
+func TestLoadMigratesFileWithBackup(t *testing.T) {
+    cfg := newTestConfig(t, "[mapping]\npref = [\"10010\"]\n")
+
+    if subject := strings.Join(cfg.GetStringArray(MappingPerf), ","); subject != "10010" {
+        t.Errorf(`GetStringArray(MappingPerf) = %q, want match for %q`, subject, "10010")
+    }
+
+    data, err := os.ReadFile(cfg.globalPath())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if subject := string(data); strings.Contains(subject, "pref") || !strings.Contains(subject, "version = 2") {
+        t.Errorf(`global config = %q, want migrated file`, subject)
+    }
+
+    if _, err = os.Stat(cfg.globalPath() + ".v1.bak"); err != nil {
+        t.Errorf(`backup is missing: %v`, err)
+    }
+}
+
+func TestMigrateDryRunKeepsFile(t *testing.T) {
+    cfg := New()
+    cfg.homeDir = t.TempDir()
+    cfg.isDeferred = true
+
+    original := "[mapping]\npref = [\"10010\"]\n"
+    path := cfg.globalPath()
+    if err := os.MkdirAll(strings.TrimSuffix(path, "/twig.toml"), 0o755); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := cfg.load(); err != nil {
+        t.Fatal(err)
+    }
+
+    migrations, err := cfg.Migrate(true)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if len(migrations) != 1 || migrations[0].To != CurrentVersion {
+        t.Errorf(`Migrate(true) = %+v, want one migration`, migrations)
+    }
+
+    if data, _ := os.ReadFile(path); string(data) != original {
+        t.Errorf(`global config = %q, want untouched`, string(data))
+    }
+}
+
+func TestLoadKeepsFileWithoutChanges(t *testing.T) {
+    original := "[branch]\ndefault = \"main\"\n"
+    cfg := newTestConfig(t, original)
+
+    if data, _ := os.ReadFile(cfg.globalPath()); string(data) != original {
+        t.Errorf(`global config = %q, want untouched`, string(data))
+    }
+
+    if _, err := os.Stat(cfg.globalPath() + ".v1.bak"); err == nil {
+        t.Errorf(`backup exists, want none for a file without changes`)
+    }
+}
+
+func TestLoadKeepsRepoFile(t *testing.T) {
+    cfg := newTestConfig(t, "")
+
+    path := filepath.Join(t.TempDir(), ".twig.toml")
+    original := "[mapping]\npref = [\"10010\"]\n"
+    if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := cfg.mergeFile(Layer{Name: LayerRepo, Path: path}, true); err != nil {
+        t.Fatal(err)
+    }
+
+    if subject := strings.Join(cfg.GetStringArray(MappingPerf), ","); subject != "10010" {
+        t.Errorf(`GetStringArray(MappingPerf) = %q, want match for %q`, subject, "10010")
+    }
+
+    if data, _ := os.ReadFile(path); string(data) != original {
+        t.Errorf(`repo config = %q, want untouched`, string(data))
+    }
+}
+
+func TestLoadMigratesReadOnlyFileInMemory(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "twig.toml")
+    if err := os.WriteFile(path, []byte("[mapping]\npref = [\"10010\"]\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    // the backup can't be written, like next to a read-only mount
+    if err := os.Mkdir(path+".v1.bak", 0o755); err != nil {
+        t.Fatal(err)
+    }
+
+    cfg := New()
+    cfg.homeDir = t.TempDir()
+    cfg.file = path
+
+    if err := cfg.load(); err != nil {
+        t.Fatalf(`load() = %v, want the read-only file migrated in memory`, err)
+    }
+
+    if subject := strings.Join(cfg.GetStringArray(MappingPerf), ","); subject != "10010" {
+        t.Errorf(`GetStringArray(MappingPerf) = %q, want match for %q`, subject, "10010")
+    }
+}
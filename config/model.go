@@ -1,16 +1,33 @@
 package config
 
 type Settings struct {
-	Project ProjectSettings `mapstructure:"project"`
-	Branch  BranchSettings  `mapstructure:"branch"`
-	Mapping MappingSettings `mapstructure:"mapping"`
+	Project   ProjectSettings            `mapstructure:"project"`
+	Profiles  map[string]ProfileSettings `mapstructure:"profiles"`
+	Branch    BranchSettings             `mapstructure:"branch"`
+	Mapping   MappingSettings            `mapstructure:"mapping"`
+	Clean     CleanSettings              `mapstructure:"clean"`
+	Issue     IssueSettings              `mapstructure:"issue"`
+	Workspace WorkspaceSettings          `mapstructure:"workspace"`
+	Commit    CommitSettings             `mapstructure:"commit"`
+	Pr        PrSettings                 `mapstructure:"pr"`
+	Git       GitSettings                `mapstructure:"git"`
 }
 
 type ProjectSettings struct {
-	Host  string `mapstructure:"host"`
-	Auth  string `mapstructure:"auth"`
-	Email string `mapstructure:"email"`
-	Token string `mapstructure:"token"`
+	Host    string `mapstructure:"host"`
+	Auth    string `mapstructure:"auth"`
+	Email   string `mapstructure:"email"`
+	Token   string `mapstructure:"token"`
+	Profile string `mapstructure:"profile"`
+}
+
+type ProfileSettings struct {
+	Host    string   `mapstructure:"host"`
+	Auth    string   `mapstructure:"auth"`
+	Email   string   `mapstructure:"email"`
+	Token   string   `mapstructure:"token"`
+	Remotes []string `mapstructure:"remotes"`
+	Paths   []string `mapstructure:"paths"`
 }
 
 type BranchSettings struct {
@@ -26,9 +43,40 @@ type MappingSettings struct {
 	Docs     []string `mapstructure:"docs"`
 	Feat     []string `mapstructure:"feat"`
 	Fix      []string `mapstructure:"fix"`
-	Pref     []string `mapstructure:"pref"`
+	Perf     []string `mapstructure:"perf"`
 	Refactor []string `mapstructure:"refactor"`
 	Revert   []string `mapstructure:"revert"`
 	Style    []string `mapstructure:"style"`
 	Test     []string `mapstructure:"test"`
 }
+
+type CleanSettings struct {
+	Protected []string `mapstructure:"protected"`
+}
+
+type IssueSettings struct {
+	Projects   []string `mapstructure:"projects"`
+	Separators []string `mapstructure:"separators"`
+	Position   string   `mapstructure:"position"`
+	Sprint     string   `mapstructure:"sprint"`
+}
+
+type WorkspaceSettings struct {
+	Repos []string `mapstructure:"repos"`
+}
+
+type CommitSettings struct {
+	Template string `mapstructure:"template"`
+}
+
+type PrSettings struct {
+	Provider  string   `mapstructure:"provider"`
+	Api       string   `mapstructure:"api"`
+	Token     string   `mapstructure:"token"`
+	Reviewers []string `mapstructure:"reviewers"`
+	Template  string   `mapstructure:"template"`
+}
+
+type GitSettings struct {
+	Backend string `mapstructure:"backend"`
+}
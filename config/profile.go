@@ -0,0 +1,146 @@
+package config
+
+import (
+    "fmt"
+    "github.com/mitchellh/go-homedir"
+    "maps"
+    "os"
+    "path/filepath"
+    "slices"
+    "twig/log"
+    "twig/util"
+)
+
+const profilesKey = "profiles"
+
+// profileKeys are the [project] keys a profile may replace.
+var profileKeys = []string{"host", "auth", "email", "token"}
+
+// ActiveProfile returns the name of the profile applied on top of [project], if any.
+func ActiveProfile() string {
+    return c.active
+}
+
+// Profiles returns the names of all configured profiles.
+func Profiles() []string {
+    return c.Profiles()
+}
+
+func (c *Config) Profiles() []string {
+    return slices.Sorted(maps.Keys(c.manager.GetStringMap(profilesKey)))
+}
+
+// HasProfile reports whether [profiles.<name>] is configured.
+func HasProfile(name string) bool {
+    return slices.Contains(Profiles(), name)
+}
+
+// selectProfile picks the --profile flag first, then a profile matching the repository
+// remote or path and finally the profile chosen with "twig config use".
+func (c *Config) selectProfile() (string, string) {
+    if c.profile != "" {
+        return c.profile, "flag"
+    }
+
+    remoteUrl := c.remoteUrl()
+    dir := c.repoRoot()
+
+    for _, name := range c.Profiles() {
+        prefix := fmt.Sprintf("%s.%s.", profilesKey, name)
+
+        for _, pattern := range c.manager.GetStringSlice(prefix + "remotes") {
+            if remoteUrl != "" && util.MatchGlob(pattern, remoteUrl) {
+                return name, fmt.Sprintf("remote %q", remoteUrl)
+            }
+        }
+
+        for _, pattern := range c.manager.GetStringSlice(prefix + "paths") {
+            expanded, err := homedir.Expand(pattern)
+            if err != nil {
+                continue
+            }
+
+            if dir != "" && util.MatchGlob(filepath.Clean(expanded), dir) {
+                return name, fmt.Sprintf("path %q", dir)
+            }
+        }
+    }
+
+    return c.manager.GetString(FromToken(ProjectProfile)), FromToken(ProjectProfile)
+}
+
+func (c *Config) applyProfile() error {
+    name, reason := c.selectProfile()
+    if name == "" {
+        return nil
+    }
+
+    if !slices.Contains(c.Profiles(), name) {
+        return fmt.Errorf("profile %q does not exist, add [%s.%s]", name, profilesKey, name)
+    }
+
+    layer := Layer{Name: LayerProfile, Path: name}
+    values := c.manager.GetStringMap(fmt.Sprintf("%s.%s", profilesKey, name))
+    project := make(map[string]any)
+
+    for _, key := range profileKeys {
+        value, ok := values[key]
+        if !ok {
+            continue
+        }
+
+        project[key] = value
+
+        // environment variables and flags still win over the profile
+        projectKey := fmt.Sprintf("%s.%s", FromToken(Project), key)
+        if source, ok := c.sources[projectKey]; !ok || layerIndex(source.Name) < layerIndex(LayerProfile) {
+            c.sources[projectKey] = layer
+        }
+    }
+
+    err := c.manager.MergeConfigMap(map[string]any{
+        FromToken(Project): project,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to apply profile %q: %w", name, err)
+    }
+
+    c.active = name
+    c.layers = append(c.layers, layer)
+    log.Debug().Println(fmt.Sprintf("Using profile %q selected by %s", name, reason))
+
+    return nil
+}
+
+func (c *Config) remoteUrl() string {
+    remote := c.manager.GetString(FromToken(BranchOrigin))
+    if remote == "" {
+        return ""
+    }
+
+    repo, err := c.repository()
+    if err != nil {
+        return ""
+    }
+
+    url, err := repo.RemoteURL(remote)
+    if err != nil {
+        return ""
+    }
+
+    return url
+}
+
+// repoRoot returns the repository root, or the working directory outside of a repository.
+func (c *Config) repoRoot() string {
+    if root := c.gitRoot(); root != "" {
+        return filepath.Clean(root)
+    }
+
+    dir, err := os.Getwd()
+    if err != nil {
+        return ""
+    }
+
+    return dir
+}
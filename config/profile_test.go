@@ -0,0 +1,83 @@
+package config
+
+import (
+    "testing"
+)
+
+const profilesConfig = `
+[project]
+host = "default.atlassian.net"
+email = "example.user@example.com"
+profile = "internal"
+
+[profiles.acme]
+host = "acme.atlassian.net"
+token = "keyring:twig-acme"
+
+[profiles.internal]
+host = "internal.atlassian.net"
+paths = ["/nowhere/*"]
+`
+
+func TestApplyProfileFromConfig(t *testing.T) {
+    cfg := newTestConfig(t, profilesConfig)
+
+    want := "internal.atlassian.net"
+    subject := cfg.GetString(ProjectHost)
+
+    if subject != want {
+        t.Errorf(`GetString(ProjectHost) = %q, want match for %q`, subject, want)
+    }
+
+    if source := cfg.Source(ProjectHost); source.Name != LayerProfile || source.Path != "internal" {
+        t.Errorf(`Source(ProjectHost) = %q, want match for %q`, source, "profile:internal")
+    }
+}
+
+func TestApplyProfileFromFlag(t *testing.T) {
+    cfg := newTestConfig(t, profilesConfig)
+    cfg.profile = "acme"
+
+    if err := cfg.load(); err != nil {
+        t.Fatal(err)
+    }
+
+    want := "acme.atlassian.net"
+    subject := cfg.GetString(ProjectHost)
+
+    if subject != want {
+        t.Errorf(`GetString(ProjectHost) = %q, want match for %q`, subject, want)
+    }
+
+    // keys missing in the profile come from [project]
+    wantEmail := "example.user@example.com"
+    if email := cfg.GetString(ProjectEmail); email != wantEmail {
+        t.Errorf(`GetString(ProjectEmail) = %q, want match for %q`, email, wantEmail)
+    }
+}
+
+func TestApplyProfileEnvWins(t *testing.T) {
+    t.Setenv("TWIG_PROJECT_HOST", "env.atlassian.net")
+
+    cfg := newTestConfig(t, profilesConfig)
+
+    want := "env.atlassian.net"
+    subject := cfg.GetString(ProjectHost)
+
+    if subject != want {
+        t.Errorf(`GetString(ProjectHost) = %q, want match for %q`, subject, want)
+    }
+
+    if source := cfg.Source(ProjectHost).Name; source != LayerEnv {
+        t.Errorf(`Source(ProjectHost) = %q, want match for %q`, source, LayerEnv)
+    }
+}
+
+func TestApplyProfileUnknown(t *testing.T) {
+    cfg := newTestConfig(t, profilesConfig)
+    cfg.profile = "unknown"
+
+    if err := cfg.load(); err == nil {
+        t.Errorf(`load() = nil, want error`)
+    }
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "strings"
+    "twig/git"
+)
+
+// Token identifies a registered config key, see register.
+type Token int
+
+const Unspecified Token = -1
+
+type Kind int
+
+const (
+    KindSection Kind = iota // groups keys, e.g. [branch]
+    KindString
+    KindArray
+)
+
+// Key describes a config option. Every command (get, set, list, validate, init) is driven by
+// the registry, so a new option is a single register call.
+type Key struct {
+    Token       Token
+    Name        string
+    Kind        Kind
+    Default     any // string or []string, sections have none
+    Secret      bool
+    Description string
+    Flag        string   // flag of twig init, e.g. --host
+    Prompt      string   // question asked by twig init, keys without it are not asked
+    Example     string   // shown in prompts and suggested fixes
+    Values      []string // allowed values, checked case-insensitively
+    Validate    func(value string) error
+}
+
+var registry []Key
+
+var (
+    Project        = register(Key{Name: "project", Kind: KindSection, Description: "Jira connection"})
+    ProjectHost    = register(Key{Name: "project.host", Kind: KindString, Description: "Jira hostname without scheme", Flag: "host", Prompt: "What is your JIRA host?", Example: "example.atlassian.net", Validate: ValidateHost})
+    ProjectAuth    = register(Key{Name: "project.auth", Kind: KindString, Description: "basic for Jira Cloud API tokens, bearer for Data Center PATs", Flag: "auth", Prompt: "What is your Jira Auth type?", Values: []string{"basic", "bearer"}})
+    ProjectEmail   = register(Key{Name: "project.email", Kind: KindString, Description: "email of the Jira account", Flag: "email", Prompt: "What is your email in Jira?", Example: "example@exp.com", Validate: ValidateEmail})
+    ProjectToken   = register(Key{Name: "project.token", Kind: KindString, Secret: true, Description: "Jira token or a keyring:, helper: or file: reference", Flag: "token", Prompt: "What is your Jira PAT?", Example: "dXNlckBleGFtcGx", Validate: validateNotEmpty})
+    ProjectProfile = register(Key{Name: "project.profile", Kind: KindString, Description: "profile used when no profile matches the repository"})
+
+    Branch        = register(Key{Name: "branch", Kind: KindSection, Description: "branch naming and remotes"})
+    BranchDefault = register(Key{Name: "branch.default", Kind: KindString, Default: "development", Description: "branch new branches start from", Flag: "default-branch", Prompt: "What branch do you use as default?", Example: "development", Validate: validateNotEmpty})
+    BranchOrigin  = register(Key{Name: "branch.origin", Kind: KindString, Default: "origin", Description: "remote to fetch from and push to", Flag: "origin", Prompt: "What remote do you use as default?", Example: "origin", Validate: validateNotEmpty})
+    BranchExclude = register(Key{Name: "branch.exclude", Kind: KindArray, Default: []string{"front", "mobile", "android", "ios", "be", "web", "spike", "eval"}, Description: "words removed from branch names", Flag: "exclude", Prompt: "Exclude any words from the branch name?", Example: "be,mobile,web"})
+
+    Mapping         = register(Key{Name: "mapping", Kind: KindSection, Description: "Jira issue type ids of every branch type"})
+    MappingBuild    = register(mappingKey("build"))
+    MappingChore    = register(mappingKey("chore"))
+    MappingCi       = register(mappingKey("ci"))
+    MappingDocs     = register(mappingKey("docs"))
+    MappingFeat     = register(mappingKey("feat"))
+    MappingFix      = register(mappingKey("fix"))
+    MappingPerf     = register(mappingKey("perf"))
+    MappingRefactor = register(mappingKey("refactor"))
+    MappingRevert   = register(mappingKey("revert"))
+    MappingStyle    = register(mappingKey("style"))
+    MappingTest     = register(mappingKey("test"))
+
+    Clean          = register(Key{Name: "clean", Kind: KindSection, Description: "twig clean"})
+    CleanProtected = register(Key{Name: "clean.protected", Kind: KindArray, Default: []string{"main", "master", "release/*", "hotfix/*"}, Description: "glob patterns of branches twig clean never deletes"})
+
+    Issue           = register(Key{Name: "issue", Kind: KindSection, Description: "issue key extraction from branch names"})
+    IssueProjects   = register(Key{Name: "issue.projects", Kind: KindArray, Description: "Jira project keys, empty for any"})
+    IssueSeparators = register(Key{Name: "issue.separators", Kind: KindArray, Default: []string{"_", "-", "."}, Description: "characters around issue keys", Validate: validateSeparator})
+    IssuePosition   = register(Key{Name: "issue.position", Kind: KindString, Default: "any", Description: "where issue keys appear in branch names", Values: []string{"any", "start"}})
+    IssueSprint     = register(Key{Name: "issue.sprint", Kind: KindString, Default: "customfield_10020", Description: "id of the Jira field holding the sprint, empty to not fetch it", Example: "customfield_10020"})
+
+    Workspace      = register(Key{Name: "workspace", Kind: KindSection, Description: "repositories cleaned together"})
+    WorkspaceRepos = register(Key{Name: "workspace.repos", Kind: KindArray, Description: "repository paths or glob patterns"})
+
+    Commit         = register(Key{Name: "commit", Kind: KindSection, Description: "commit messages of the commit hooks"})
+    CommitTemplate = register(Key{Name: "commit.template", Kind: KindString, Default: "{type}({key}): {message}", Description: "format of commit messages, {type} and {key} are taken from the branch", Example: "{type}({key}): {message}", Validate: validateCommitTemplate})
+
+    Pr          = register(Key{Name: "pr", Kind: KindSection, Description: "pull requests opened by twig pr"})
+    PrProvider  = register(Key{Name: "pr.provider", Kind: KindString, Default: "auto", Description: "hosting of the repository, auto tells it by the remote URL", Values: []string{"auto", "github", "gitlab", "bitbucket"}})
+    PrApi       = register(Key{Name: "pr.api", Kind: KindString, Description: "base URL of the REST API, empty for the API of the remote host", Example: "https://git.example.com/api/v4", Validate: validateApiUrl})
+    PrToken     = register(Key{Name: "pr.token", Kind: KindString, Secret: true, Description: "GitHub, GitLab or Bitbucket token or a keyring:, helper: or file: reference"})
+    PrReviewers = register(Key{Name: "pr.reviewers", Kind: KindArray, Description: "usernames requested to review, org/team on GitHub, account ids or {uuid}s on Bitbucket"})
+    PrTemplate  = register(Key{Name: "pr.template", Kind: KindString, Default: "[{key}]({url})\n\n{description}", Description: "body of pull requests, {key}, {url}, {summary} and {description} are taken from the issue", Example: "Closes [{key}]({url})"})
+
+    Git        = register(Key{Name: "git", Kind: KindSection, Description: "access to git repositories"})
+    GitBackend = register(Key{Name: "git.backend", Kind: KindString, Default: git.BackendExec, Description: "exec runs the git binary, go-git works without it", Values: []string{git.BackendExec, git.BackendGoGit}})
+)
+
+func register(key Key) Token {
+    key.Token = Token(len(registry))
+
+    if key.Default == nil {
+        switch key.Kind {
+        case KindString:
+            key.Default = ""
+        case KindArray:
+            key.Default = []string{}
+        }
+    }
+
+    registry = append(registry, key)
+    return key.Token
+}
+
+func mappingKey(name string) Key {
+    return Key{
+        Name:        fmt.Sprintf("mapping.%s", name),
+        Kind:        KindArray,
+        Default:     []string{"0"},
+        Description: fmt.Sprintf("Jira issue type ids of %q branches, 0 to ignore", name),
+        Flag:        fmt.Sprintf("mapping-%s", name),
+        Prompt:      fmt.Sprintf("What should %q be mapped to?", name),
+        Example:     "101,102",
+        Validate:    ValidateIssueTypeId,
+    }
+}
+
+// Tokens returns every token, sections included.
+func Tokens() []Token {
+    tokens := make([]Token, len(registry))
+    for i, key := range registry {
+        tokens[i] = key.Token
+    }
+
+    return tokens
+}
+
+// Keys returns every key which holds a value, in registration order.
+func Keys() []Key {
+    keys := make([]Key, 0, len(registry))
+    for _, key := range registry {
+        if key.Kind != KindSection {
+            keys = append(keys, key)
+        }
+    }
+
+    return keys
+}
+
+// KeysOf returns the keys of the section.
+func KeysOf(section Token) []Key {
+    prefix := FromToken(section) + "."
+
+    keys := make([]Key, 0)
+    for _, key := range Keys() {
+        if strings.HasPrefix(key.Name, prefix) {
+            keys = append(keys, key)
+        }
+    }
+
+    return keys
+}
+
+// Lookup returns the registered key of the token.
+func Lookup(token Token) Key {
+    if token < 0 || int(token) >= len(registry) {
+        return Key{Token: Unspecified}
+    }
+
+    return registry[token]
+}
+
+// IsArray reports whether the token holds a list of values.
+func IsArray(token Token) bool {
+    return Lookup(token).Kind == KindArray
+}
+
+func FromToken(token Token) string {
+    return Lookup(token).Name
+}
+
+func FromInput(token string) (Token, error) {
+    for _, key := range registry {
+        if key.Name == token {
+            return key.Token, nil
+        }
+    }
+
+    return Unspecified, errors.New("unexpected token from input")
+}
+
+// Parse converts user input into the value stored for the key: arrays are split by commas,
+// allowed values are lower-cased and every value is validated.
+func (k Key) Parse(input string) (any, error) {
+    switch k.Kind {
+    case KindArray:
+        if strings.TrimSpace(input) == "" {
+            return []string{}, nil
+        }
+
+        values := splitValues(input)
+        for i := range values {
+            value, err := k.check(values[i])
+            if err != nil {
+                return nil, err
+            }
+            values[i] = value
+        }
+        return values, nil
+    case KindString:
+        return k.check(strings.TrimSpace(input))
+    default:
+        return nil, fmt.Errorf("%q is a section, set one of its keys", k.Name)
+    }
+}
+
+func (k Key) check(value string) (string, error) {
+    if len(k.Values) > 0 {
+        value = strings.ToLower(value)
+        if !slices.Contains(k.Values, value) {
+            return "", fmt.Errorf("%q is not one of %s", value, strings.Join(k.Values, ", "))
+        }
+    }
+
+    if k.Validate != nil {
+        if err := k.Validate(value); err != nil {
+            return "", err
+        }
+    }
+
+    return value, nil
+}
+
+// Question returns the init prompt along with an example or the allowed values.
+func (k Key) Question() string {
+    switch {
+    case len(k.Values) > 0:
+        return fmt.Sprintf("%s (%s): ", k.Prompt, strings.Join(k.Values, "/"))
+    case k.Example != "":
+        return fmt.Sprintf("%s (e.g. %s): ", k.Prompt, k.Example)
+    default:
+        return fmt.Sprintf("%s: ", k.Prompt)
+    }
+}
+
+// Suggestion returns an example value for fixes, e.g. "twig config set <key> <suggestion>".
+func (k Key) Suggestion() string {
+    if len(k.Values) > 0 {
+        return strings.Join(k.Values, "|")
+    }
+
+    if k.Example != "" {
+        return k.Example
+    }
+
+    return "<value>"
+}
+
+func validateNotEmpty(value string) error {
+    if strings.TrimSpace(value) == "" {
+        return errors.New("value is empty")
+    }
+
+    return nil
+}
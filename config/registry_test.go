@@ -0,0 +1,110 @@
+package config
+
+import (
+    "bytes"
+    "reflect"
+    "slices"
+    "testing"
+    "github.com/spf13/viper"
+)
+
+func TestRegistryMatchesEmbeddedConfig(t *testing.T) {
+    v := viper.New()
+    v.SetConfigType("toml")
+
+    if err := v.ReadConfig(bytes.NewReader(embededCfg)); err != nil {
+        t.Fatal(err)
+    }
+
+    for _, name := range v.AllKeys() {
+        if name == versionKey {
+            continue
+        }
+
+        token, err := FromInput(name)
+        if err != nil {
+            t.Errorf(`FromInput(%q) = %v, want registered key`, name, err)
+            continue
+        }
+
+        key := Lookup(token)
+
+        isEqual := v.GetString(name) == key.Default
+        if key.Kind == KindArray {
+            isEqual = slices.Equal(v.GetStringSlice(name), key.Default.([]string))
+        }
+
+        if !isEqual {
+            t.Errorf(`Lookup(%q).Default = %v, want match for %v`, name, key.Default, v.Get(name))
+        }
+    }
+}
+
+func TestFromInputRoundTrip(t *testing.T) {
+    for _, token := range Tokens() {
+        subject, err := FromInput(FromToken(token))
+
+        if err != nil || subject != token {
+            t.Errorf(`FromInput(FromToken(%d)) = %d, %v, want match for %d`, token, subject, err, token)
+        }
+    }
+}
+
+func TestFromInputUnknownKey(t *testing.T) {
+    if subject, err := FromInput("mapping.unknown"); err == nil {
+        t.Errorf(`FromInput("mapping.unknown") = %d, want error`, subject)
+    }
+}
+
+func TestKeysOfSection(t *testing.T) {
+    want := []string{"branch.default", "branch.origin", "branch.exclude"}
+
+    subject := make([]string, 0)
+    for _, key := range KeysOf(Branch) {
+        subject = append(subject, key.Name)
+    }
+
+    if !slices.Equal(subject, want) {
+        t.Errorf(`KeysOf(Branch) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestParseAllowedValue(t *testing.T) {
+    want := "bearer"
+    subject, err := Lookup(ProjectAuth).Parse(" Bearer ")
+
+    if err != nil || subject != want {
+        t.Errorf(`Parse(" Bearer ") = %q, %v, want match for %q`, subject, err, want)
+    }
+
+    if _, err = Lookup(ProjectAuth).Parse("token"); err == nil {
+        t.Errorf(`Parse("token") = nil error, want error`)
+    }
+}
+
+func TestParseArray(t *testing.T) {
+    want := []string{"101", "102"}
+    subject, err := Lookup(MappingFix).Parse("101, 102")
+
+    if err != nil || !reflect.DeepEqual(subject, want) {
+        t.Errorf(`Parse("101, 102") = %q, %v, want match for %q`, subject, err, want)
+    }
+
+    if _, err = Lookup(MappingFix).Parse("101,story"); err == nil {
+        t.Errorf(`Parse("101,story") = nil error, want error`)
+    }
+}
+
+func TestParseEmptyArray(t *testing.T) {
+    subject, err := Lookup(BranchExclude).Parse("")
+
+    if err != nil || !reflect.DeepEqual(subject, []string{}) {
+        t.Errorf(`Parse("") = %q, %v, want empty array`, subject, err)
+    }
+}
+
+func TestParseSection(t *testing.T) {
+    if _, err := Lookup(Mapping).Parse("1"); err == nil {
+        t.Errorf(`Parse() of section = nil error, want error`)
+    }
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "github.com/spf13/viper"
+    "io"
+    "maps"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+    "twig/log"
+)
+
+// TargetPath returns the file written by Set* functions and creates it when missing,
+// the global config starts from the embedded template.
+func TargetPath() (string, error) {
+    return c.TargetPath()
+}
+
+func (c *Config) TargetPath() (string, error) {
+    target := c.targetLayer()
+    if _, err := os.Stat(target.Path); err == nil {
+        return target.Path, nil
+    }
+
+    if err := os.MkdirAll(filepath.Dir(target.Path), 0o755); err != nil {
+        return "", fmt.Errorf("failed to create %s config: %w", target.Name, err)
+    }
+
+    content := []byte{}
+    if target.Name == LayerGlobal {
+        content = embededCfg
+    }
+
+    if err := os.WriteFile(target.Path, content, 0o644); err != nil {
+        return "", fmt.Errorf("failed to create %s config: %w", target.Name, err)
+    }
+
+    return target.Path, nil
+}
+
+// Unset removes the key (or a whole section) from the target layer, so lower layers provide its value again.
+func Unset(token Token) error {
+    return c.Unset(token)
+}
+
+func (c *Config) Unset(token Token) error {
+    key := Lookup(token)
+    if key.Token == Unspecified {
+        return errors.New("unexpected token from input")
+    }
+
+    target := c.targetLayer()
+    v, err := c.readTarget()
+    if err != nil {
+        return err
+    }
+
+    settings := v.AllSettings()
+    if !deleteKey(settings, strings.Split(key.Name, ".")) {
+        return fmt.Errorf("%q is not set in %s config", key.Name, target)
+    }
+
+    out := viper.New()
+    out.SetConfigType(c.ext)
+    if err = out.MergeConfigMap(settings); err != nil {
+        return fmt.Errorf("failed to unset %q: %w", key.Name, err)
+    }
+
+    if err = out.WriteConfigAs(target.Path); err != nil {
+        return fmt.Errorf("failed to save config: %w", err)
+    }
+
+    // values of the lower layers take over
+    return c.load()
+}
+
+// Export writes the merged value of every key of the sections (all keys when none are given)
+// as TOML. Secrets are stripped, so the result can be committed as a team baseline.
+func Export(w io.Writer, sections ...Token) error {
+    return c.Export(w, sections...)
+}
+
+func (c *Config) Export(w io.Writer, sections ...Token) error {
+    v := viper.New()
+    v.SetConfigType(c.ext)
+    v.Set(versionKey, CurrentVersion)
+
+    keys := Keys()
+    if len(sections) > 0 {
+        keys = make([]Key, 0)
+        for _, section := range sections {
+            keys = append(keys, KeysOf(section)...)
+        }
+    }
+
+    for _, key := range keys {
+        if key.Secret {
+            continue
+        }
+
+        if key.Kind == KindArray {
+            v.Set(key.Name, c.GetStringArray(key.Token))
+        } else {
+            v.Set(key.Name, c.GetString(key.Token))
+        }
+    }
+
+    if len(sections) == 0 {
+        for _, name := range c.Profiles() {
+            prefix := fmt.Sprintf("%s.%s", profilesKey, name)
+            for field, value := range c.manager.GetStringMap(prefix) {
+                if field != "token" {
+                    v.Set(fmt.Sprintf("%s.%s", prefix, field), value)
+                }
+            }
+        }
+    }
+
+    if err := v.WriteConfigTo(w); err != nil {
+        return fmt.Errorf("failed to export config: %w", err)
+    }
+
+    return nil
+}
+
+// Import merges a shared config file into the target layer. Secrets and unknown keys are skipped
+// and values are checked like "twig config set". It returns the imported keys.
+func Import(path string) ([]string, error) {
+    return c.Import(path)
+}
+
+func (c *Config) Import(path string) ([]string, error) {
+    in := viper.New()
+    in.SetConfigFile(path)
+    in.SetConfigType(c.ext)
+
+    if err := in.ReadInConfig(); err != nil {
+        return nil, fmt.Errorf("failed to read %q: %w", path, err)
+    }
+
+    settings := in.AllSettings()
+    migrateSettings(Layer{Name: "import", Path: path}, settings)
+
+    in = viper.New()
+    if err := in.MergeConfigMap(settings); err != nil {
+        return nil, fmt.Errorf("failed to migrate %q: %w", path, err)
+    }
+
+    values := make(map[string]any)
+    for _, name := range in.AllKeys() {
+        value, err := importValue(in, name)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", name, err)
+        }
+
+        if value == nil {
+            if name == versionKey {
+                continue
+            }
+            log.Warn().Println(fmt.Sprintf("Skip %q, secrets and unknown keys are not imported", name))
+            continue
+        }
+
+        if c.targetLayer().Name == LayerRepo && !IsRepoKey(name) {
+            log.Warn().Println(fmt.Sprintf("Skip %q, a repository may only set %s", name, repoSectionNames()))
+            continue
+        }
+
+        values[name] = value
+    }
+
+    target := c.targetLayer()
+    v, err := c.readTarget()
+    if err != nil {
+        return nil, err
+    }
+
+    names := slices.Sorted(maps.Keys(values))
+    for _, name := range names {
+        v.Set(name, values[name])
+    }
+
+    if err = v.WriteConfigAs(target.Path); err != nil {
+        return nil, fmt.Errorf("failed to save config: %w", err)
+    }
+
+    return names, c.load()
+}
+
+// importValue returns the checked value of the key, or nothing when the key must not be imported.
+func importValue(in *viper.Viper, name string) (any, error) {
+    if name == versionKey {
+        return nil, nil
+    }
+
+    if strings.HasPrefix(name, profilesKey+".") {
+        if strings.HasSuffix(name, ".token") {
+            return nil, nil
+        }
+
+        return in.Get(name), nil
+    }
+
+    token, err := FromInput(name)
+    if err != nil || Lookup(token).Secret {
+        return nil, nil
+    }
+
+    key := Lookup(token)
+    if key.Kind == KindArray {
+        return key.Parse(strings.Join(in.GetStringSlice(name), ","))
+    }
+
+    return key.Parse(in.GetString(name))
+}
+
+func (c *Config) readTarget() (*viper.Viper, error) {
+    target := c.targetLayer()
+
+    v := viper.New()
+    v.SetConfigType(c.ext)
+    v.SetConfigFile(target.Path)
+
+    if _, err := os.Stat(target.Path); err == nil {
+        if err = v.ReadInConfig(); err != nil {
+            return nil, fmt.Errorf("failed to read %s config: %w", target.Name, err)
+        }
+    }
+
+    if !v.IsSet(versionKey) {
+        v.Set(versionKey, CurrentVersion)
+    }
+
+    return v, nil
+}
+
+// deleteKey removes the dotted path from nested settings along with sections left empty.
+func deleteKey(settings map[string]any, path []string) bool {
+    if len(path) == 1 {
+        _, ok := settings[path[0]]
+        delete(settings, path[0])
+        return ok
+    }
+
+    section, ok := settings[path[0]].(map[string]any)
+    if !ok || !deleteKey(section, path[1:]) {
+        return false
+    }
+
+    if len(section) == 0 {
+        delete(settings, path[0])
+    }
+
+    return true
+}
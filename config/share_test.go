@@ -0,0 +1,123 @@
+package config
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestUnsetRestoresDefault(t *testing.T) {
+    cfg := newTestConfig(t, "[branch]\ndefault = \"main\"\norigin = \"upstream\"\n")
+
+    if err := cfg.Unset(BranchDefault); err != nil {
+        t.Fatal(err)
+    }
+
+    want := "development"
+    subject := cfg.GetString(BranchDefault)
+
+    if subject != want {
+        t.Errorf(`GetString(BranchDefault) = %q, want match for %q`, subject, want)
+    }
+
+    if origin := cfg.GetString(BranchOrigin); origin != "upstream" {
+        t.Errorf(`GetString(BranchOrigin) = %q, want match for %q`, origin, "upstream")
+    }
+}
+
+func TestUnsetMissingKey(t *testing.T) {
+    cfg := newTestConfig(t, "[branch]\ndefault = \"main\"\n")
+
+    if err := cfg.Unset(BranchOrigin); err == nil {
+        t.Errorf(`Unset(BranchOrigin) = nil, want error`)
+    }
+}
+
+func TestDeleteKeyDropsEmptySection(t *testing.T) {
+    settings := map[string]any{
+        "branch": map[string]any{"default": "main"},
+        "issue":  map[string]any{"position": "any"},
+    }
+
+    if !deleteKey(settings, []string{"branch", "default"}) {
+        t.Fatalf(`deleteKey(branch.default) = false, want true`)
+    }
+
+    if _, ok := settings["branch"]; ok || len(settings) != 1 {
+        t.Errorf(`deleteKey(branch.default) left %v, want only issue`, settings)
+    }
+}
+
+func TestExportStripsSecrets(t *testing.T) {
+    cfg := newTestConfig(t, profilesConfig, "project.token=secret-token", "mapping.fix=10")
+
+    var out bytes.Buffer
+    if err := cfg.Export(&out); err != nil {
+        t.Fatal(err)
+    }
+
+    subject := out.String()
+    if strings.Contains(subject, "secret-token") || strings.Contains(subject, "keyring:twig-acme") {
+        t.Errorf(`Export() = %q, want no secrets`, subject)
+    }
+
+    if !strings.Contains(subject, "acme.atlassian.net") || !strings.Contains(subject, "'10'") {
+        t.Errorf(`Export() = %q, want profiles and mapping`, subject)
+    }
+}
+
+func TestExportSections(t *testing.T) {
+    cfg := newTestConfig(t, "")
+
+    var out bytes.Buffer
+    if err := cfg.Export(&out, Mapping); err != nil {
+        t.Fatal(err)
+    }
+
+    subject := out.String()
+    if !strings.Contains(subject, "[mapping]") || strings.Contains(subject, "[branch]") {
+        t.Errorf(`Export(Mapping) = %q, want only mapping`, subject)
+    }
+}
+
+func TestImportSkipsSecretsAndUnknownKeys(t *testing.T) {
+    cfg := newTestConfig(t, "[project]\nhost = \"example.atlassian.net\"\n")
+
+    path := filepath.Join(t.TempDir(), "team.toml")
+    team := "[project]\ntoken = \"team-token\"\n[mapping]\nfix = [\"10\", \"11\"]\n[branch]\nunknown = \"x\"\n"
+    if err := os.WriteFile(path, []byte(team), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    keys, err := cfg.Import(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if len(keys) != 1 || keys[0] != "mapping.fix" {
+        t.Errorf(`Import() = %q, want match for ["mapping.fix"]`, keys)
+    }
+
+    if host := cfg.GetString(ProjectHost); host != "example.atlassian.net" {
+        t.Errorf(`GetString(ProjectHost) = %q, want existing value kept`, host)
+    }
+
+    if token := cfg.GetString(ProjectToken); token != "" {
+        t.Errorf(`GetString(ProjectToken) = %q, want empty`, token)
+    }
+}
+
+func TestImportInvalidValue(t *testing.T) {
+    cfg := newTestConfig(t, "")
+
+    path := filepath.Join(t.TempDir(), "team.toml")
+    if err := os.WriteFile(path, []byte("[mapping]\nfix = [\"story\"]\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    if _, err := cfg.Import(path); err == nil {
+        t.Errorf(`Import() = nil, want error`)
+    }
+}
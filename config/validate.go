@@ -0,0 +1,212 @@
+package config
+
+import (
+    "fmt"
+    "maps"
+    "net/mail"
+    "net/url"
+    "slices"
+    "strconv"
+    "strings"
+    "twig/commit"
+)
+
+// Problem is a config value which twig can't work with, along with a way to fix it.
+type Problem struct {
+    Key     string
+    Message string
+    Fix     string
+}
+
+func (p Problem) String() string {
+    return fmt.Sprintf("%s: %s", p.Key, p.Message)
+}
+
+// Validate checks the merged config without contacting Jira.
+func Validate() []Problem {
+    problems := make([]Problem, 0)
+    problems = append(problems, validateKeys()...)
+    problems = append(problems, validateUnknown(c.manager.AllKeys())...)
+    problems = append(problems, validateProfile(GetString(ProjectProfile))...)
+    problems = append(problems, validateRemote(GetString(BranchOrigin))...)
+    problems = append(problems, validateMapping(GetStringMap(Mapping))...)
+
+    return problems
+}
+
+// validateKeys checks every value against its registered key.
+func validateKeys() []Problem {
+    problems := make([]Problem, 0)
+
+    for _, key := range Keys() {
+        values := []string{GetString(key.Token)}
+        if key.Kind == KindArray {
+            values = GetStringArray(key.Token)
+        }
+
+        for _, value := range values {
+            if _, err := key.check(value); err != nil {
+                problems = append(problems, problemOf(key, err))
+                break
+            }
+        }
+    }
+
+    return problems
+}
+
+func problemOf(key Key, err error) Problem {
+    fix := fmt.Sprintf("twig config set %s %s", key.Name, key.Suggestion())
+    if key.Secret {
+        fix = fmt.Sprintf("run twig init or export %s", EnvName(key.Token))
+    }
+
+    return Problem{Key: key.Name, Message: err.Error(), Fix: fix}
+}
+
+// validateUnknown reports keys which are not registered, e.g. typos. Profiles are free-form.
+func validateUnknown(keys []string) []Problem {
+    problems := make([]Problem, 0)
+
+    for _, key := range keys {
+        if key == versionKey || strings.HasPrefix(key, profilesKey+".") {
+            continue
+        }
+
+        if _, err := FromInput(key); err != nil {
+            problems = append(problems, Problem{
+                Key:     key,
+                Message: "unknown key",
+                Fix:     fmt.Sprintf("remove %q from %s", key, c.sources[key]),
+            })
+        }
+    }
+
+    return problems
+}
+
+func validateProfile(profile string) []Problem {
+    if profile == "" || HasProfile(profile) {
+        return nil
+    }
+
+    return []Problem{{
+        Key:     FromToken(ProjectProfile),
+        Message: fmt.Sprintf("profile %q does not exist", profile),
+        Fix:     fmt.Sprintf("add [profiles.%s] or twig config use <profile>", profile),
+    }}
+}
+
+func validateRemote(remote string) []Problem {
+    if strings.TrimSpace(remote) == "" || !isRepository() || hasRemote(remote) {
+        return nil
+    }
+
+    key := FromToken(BranchOrigin)
+    return []Problem{{
+        Key:     key,
+        Message: fmt.Sprintf("remote %q does not exist in this repository", remote),
+        Fix:     fmt.Sprintf("git remote -v, then twig config set --local %s <remote>", key),
+    }}
+}
+
+func validateMapping(mapping map[string][]string) []Problem {
+    problems := make([]Problem, 0)
+    owners := make(map[string]string)
+
+    for _, name := range slices.Sorted(maps.Keys(mapping)) {
+        key := fmt.Sprintf("%s.%s", FromToken(Mapping), name)
+
+        for _, id := range mapping[name] {
+            if id == "0" || ValidateIssueTypeId(id) != nil {
+                continue // ignored or reported by validateKeys
+            }
+
+            if owner, ok := owners[id]; ok {
+                problems = append(problems, Problem{
+                    Key:     key,
+                    Message: fmt.Sprintf("issue type %q is already mapped to %q", id, owner),
+                    Fix:     fmt.Sprintf("remove %q from one of the types", id),
+                })
+                continue
+            }
+
+            owners[id] = key
+        }
+    }
+
+    return problems
+}
+
+// ValidateHost accepts a bare hostname with an optional port, e.g. example.atlassian.net.
+func ValidateHost(host string) error {
+    if strings.TrimSpace(host) == "" {
+        return fmt.Errorf("host is empty")
+    }
+
+    u, err := url.Parse("https://" + host)
+    if err != nil || u.Host != host || u.Hostname() == "" {
+        return fmt.Errorf("host %q must be a hostname without scheme or path", host)
+    }
+
+    return nil
+}
+
+func ValidateEmail(email string) error {
+    address, err := mail.ParseAddress(email)
+    if err != nil || address.Address != email {
+        return fmt.Errorf("email %q is invalid", email)
+    }
+
+    return nil
+}
+
+func ValidateIssueTypeId(id string) error {
+    if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+        return fmt.Errorf("issue type id %q is not numeric", id)
+    }
+
+    return nil
+}
+
+// validateApiUrl accepts an empty value or an http(s) URL, e.g. https://git.example.com/api/v4.
+func validateApiUrl(api string) error {
+    if api == "" {
+        return nil
+    }
+
+    u, err := url.Parse(api)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        return fmt.Errorf("api %q must be an http or https URL", api)
+    }
+
+    return nil
+}
+
+func validateCommitTemplate(template string) error {
+    _, err := commit.NewTemplate(template)
+    return err
+}
+
+// validateSeparator accepts a single character, issue keys are delimited by one character on each side.
+func validateSeparator(separator string) error {
+    if len(separator) != 1 {
+        return fmt.Errorf("separator %q must be a single ASCII character", separator)
+    }
+
+    return nil
+}
+
+func isRepository() bool {
+    return c.gitRoot() != ""
+}
+
+func hasRemote(remote string) bool {
+    repo, err := c.repository()
+    if err != nil {
+        return false
+    }
+
+    _, err = repo.RemoteURL(remote)
+    return err == nil
+}
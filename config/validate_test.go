@@ -0,0 +1,83 @@
+package config
+
+import (
+    "testing"
+)
+
+func TestValidateHostOptimisticCase(t *testing.T) {
+    for _, in := range []string{"example.atlassian.net", "jira.example.com:8443", "localhost"} {
+        if err := ValidateHost(in); err != nil {
+            t.Errorf(`ValidateHost(%q) = %v, want nil`, in, err)
+        }
+    }
+}
+
+func TestValidateHostWithSchemeOrPath(t *testing.T) {
+    for _, in := range []string{"", "https://example.atlassian.net", "example.atlassian.net/jira", "exa mple.net"} {
+        if err := ValidateHost(in); err == nil {
+            t.Errorf(`ValidateHost(%q) = nil, want error`, in)
+        }
+    }
+}
+
+func TestValidateEmail(t *testing.T) {
+    if err := ValidateEmail("example.user@example.com"); err != nil {
+        t.Errorf(`ValidateEmail(valid) = %v, want nil`, err)
+    }
+
+    for _, in := range []string{"", "example.user", "Example <example.user@example.com>"} {
+        if err := ValidateEmail(in); err == nil {
+            t.Errorf(`ValidateEmail(%q) = nil, want error`, in)
+        }
+    }
+}
+
+func TestValidateSeparator(t *testing.T) {
+    if err := validateSeparator("_"); err != nil {
+        t.Errorf(`validateSeparator("_") = %v, want nil`, err)
+    }
+
+    for _, in := range []string{"", "--", "·"} {
+        if err := validateSeparator(in); err == nil {
+            t.Errorf(`validateSeparator(%q) = nil, want error`, in)
+        }
+    }
+}
+
+func TestValidateMappingDuplicates(t *testing.T) {
+    in := map[string][]string{
+        "feat": {"10001"},
+        "fix":  {"10001", "10002"},
+        "docs": {"0"},
+        "test": {"0"},
+    }
+
+    subject := validateMapping(in)
+
+    if len(subject) != 1 || subject[0].Key != "mapping.fix" {
+        t.Errorf(`validateMapping(in) = %v, want one problem for "mapping.fix"`, subject)
+    }
+}
+
+func TestValidateMappingSkipsNotNumeric(t *testing.T) {
+    in := map[string][]string{
+        "feat": {"story"},
+        "fix":  {"story"},
+    }
+
+    subject := validateMapping(in)
+
+    if len(subject) != 0 {
+        t.Errorf(`validateMapping(in) = %v, want no problems`, subject)
+    }
+}
+
+func TestValidateUnknownKeys(t *testing.T) {
+    in := []string{"branch.default", "branch.defualt", "profiles.acme.host"}
+
+    subject := validateUnknown(in)
+
+    if len(subject) != 1 || subject[0].Key != "branch.defualt" {
+        t.Errorf(`validateUnknown(in) = %v, want one problem for "branch.defualt"`, subject)
+    }
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BranchLocation tells where a branch exists, see Repository.FindBranch.
+type BranchLocation int
+
+const (
+	BranchMissing        BranchLocation = iota
+	BranchLocal                         // refs/heads/<name>
+	BranchRemoteTracking                // only refs/remotes/<remote>/<name>, fetched but never checked out
+	BranchRemoteOnly                    // only on the remote, not fetched yet
+)
+
+func (l BranchLocation) String() string {
+	switch l {
+	case BranchLocal:
+		return "local"
+	case BranchRemoteTracking:
+		return "remote-tracking"
+	case BranchRemoteOnly:
+		return "remote-only"
+	default:
+		return "missing"
+	}
+}
+
+// BranchInfo describes a local branch.
+type BranchInfo struct {
+	Name      string    `json:"name"`
+	Upstream  string    `json:"upstream,omitempty"` // e.g. origin/main, empty without upstream
+	Ahead     int       `json:"ahead"`              // commits not pushed to the upstream
+	Behind    int       `json:"behind"`             // commits of the upstream not pulled yet
+	Gone      bool      `json:"gone,omitempty"`     // the upstream was deleted
+	Date      time.Time `json:"date"`               // committer date of the tip
+	Author    string    `json:"author"`             // author of the tip
+	SHA       string    `json:"sha"`
+	IsCurrent bool      `json:"current"`
+	Worktree  string    `json:"worktree,omitempty"` // path of the worktree the branch is checked out in
+}
+
+// CheckedOutElsewhere reports whether the branch is checked out in another worktree, so it can't be deleted.
+func (b BranchInfo) CheckedOutElsewhere() bool {
+	return b.Worktree != "" && !b.IsCurrent
+}
+
+// branchFields are read with for-each-ref, one branch per line with fields separated by NUL.
+var branchFields = []string{
+	"%(refname:short)",
+	"%(upstream:short)",
+	"%(upstream:track,nobracket)",
+	"%(committerdate:iso-strict)",
+	"%(authorname)",
+	"%(objectname)",
+	"%(HEAD)",
+	"%(worktreepath)",
+}
+
+func branchFormat() string {
+	return "--format=" + strings.Join(branchFields, "%00")
+}
+
+func parseBranches(out []byte) ([]BranchInfo, error) {
+	branches := make([]BranchInfo, 0)
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\x00")
+		if len(fields) != len(branchFields) {
+			return nil, fmt.Errorf("git: unexpected branch %q", line)
+		}
+
+		b := BranchInfo{
+			Name:      fields[0],
+			Upstream:  fields[1],
+			Author:    fields[4],
+			SHA:       fields[5],
+			IsCurrent: fields[6] == "*",
+			Worktree:  fields[7],
+		}
+
+		if err := b.parseTrack(fields[2]); err != nil {
+			return nil, err
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("git: branch %q: %w", b.Name, err)
+		}
+		b.Date = date
+
+		branches = append(branches, b)
+	}
+
+	return branches, nil
+}
+
+// parseTrack reads "ahead 1, behind 2" or "gone" of the C locale.
+func (b *BranchInfo) parseTrack(track string) error {
+	if track == "gone" {
+		b.Gone = true
+		return nil
+	}
+
+	for _, part := range strings.Split(track, ", ") {
+		if part == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(part, " ")
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("git: branch %q: unexpected track %q", b.Name, track)
+		}
+
+		switch name {
+		case "ahead":
+			b.Ahead = n
+		case "behind":
+			b.Behind = n
+		default:
+			return fmt.Errorf("git: branch %q: unexpected track %q", b.Name, track)
+		}
+	}
+
+	return nil
+}
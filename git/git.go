@@ -2,6 +2,7 @@ package git
 
 import (
 	"errors"
+	"os"
 	"os/exec"
 )
 
@@ -10,8 +11,15 @@ type Cmd int
 const (
 	Branch = iota
 	Checkout
+	Commit
 	Fetch
+	ForEachRef
+	LsRemote
+	MergeBase
 	Push
+	Remote
+	RevParse
+	ShowRef
 	Status
 	Version
 )
@@ -22,6 +30,9 @@ const directive = "git"
 type Git struct {
 	name    string
 	arg     []string
+	dir     string
+	env     []string
+	stdio   bool
 	Err     error
 }
 
@@ -40,12 +51,38 @@ func Command(cmd Cmd, arg ...string) *Git {
 	return git
 }
 
+// In runs the command in dir instead of the working directory.
+func (g *Git) In(dir string) *Git {
+	g.dir = dir
+	return g
+}
+
+// Untranslated runs the command with the C locale, for output which is parsed.
+func (g *Git) Untranslated() *Git {
+	g.env = append(g.env, "LC_ALL=C")
+	return g
+}
+
+// Interactive connects the command to the terminal, e.g. for an editor or the output of hooks.
+func (g *Git) Interactive() *Git {
+	g.stdio = true
+	return g
+}
+
 func (g *Git) Run() error {
 	if g.Err != nil {
 		return g.Err
 	}
 
-	return exec.Command(g.name, g.arg...).Run()
+	return g.command().Run()
+}
+
+func (g *Git) Output() ([]byte, error) {
+	if g.Err != nil {
+		return []byte{}, g.Err
+	}
+
+	return g.command().Output()
 }
 
 func (g *Git) CombinedOutput() ([]byte, error) {
@@ -53,7 +90,7 @@ func (g *Git) CombinedOutput() ([]byte, error) {
 		return []byte{}, g.Err
 	}
 
-	return exec.Command(g.name, g.arg...).CombinedOutput()
+	return g.command().CombinedOutput()
 }
 
 func fromCommand(cmd Cmd) (string, error) {
@@ -62,10 +99,24 @@ func fromCommand(cmd Cmd) (string, error) {
 		return "branch", nil
 	case Checkout:
 		return "checkout", nil
+	case Commit:
+		return "commit", nil
 	case Fetch:
 		return "fetch", nil
+	case ForEachRef:
+		return "for-each-ref", nil
+	case LsRemote:
+		return "ls-remote", nil
+	case MergeBase:
+		return "merge-base", nil
 	case Push:
 		return "push", nil
+	case Remote:
+		return "remote", nil
+	case RevParse:
+		return "rev-parse", nil
+	case ShowRef:
+		return "show-ref", nil
 	case Status:
 		return "status", nil
 	case Version:
@@ -74,3 +125,17 @@ func fromCommand(cmd Cmd) (string, error) {
 		return "", errors.New("git: undefined command")
 	}
 }
+
+func (g *Git) command() *exec.Cmd {
+	command := exec.Command(g.name, g.arg...)
+	command.Dir = g.dir
+	if len(g.env) > 0 {
+		command.Env = append(os.Environ(), g.env...)
+	}
+
+	if g.stdio {
+		command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+	}
+
+	return command
+}
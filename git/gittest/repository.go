@@ -0,0 +1,335 @@
+// Package gittest provides an in-memory git.Repository for tests of commands.
+package gittest
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"twig/git"
+)
+
+var _ git.Repository = (*Repository)(nil)
+
+// Repository keeps branches in memory and records every change as the git command it stands for,
+// e.g. "checkout -b feat/ABC-1" or "push -d origin feat/ABC-1".
+type Repository struct {
+	Current  string
+	Local    []string
+	Info     map[string]git.BranchInfo // details of local branches, e.g. the upstream, set by Push
+	Remote   map[string][]string       // branches of every remote
+	Tracking map[string][]string       // remote-tracking branches of every remote, updated by Fetch and Push
+	Merged   map[string][]string       // branches every branch is merged into
+	Dirty    bool
+	Hooks    string            // directory returned by HooksDir
+	Dir      string            // top-level directory returned by Root
+	URLs     map[string]string // URLs of the remotes
+	Messages []string          // messages of every commit
+	Errors   map[string]error  // errors returned by the methods with that name, e.g. "Push"
+	Commands []string
+}
+
+// New returns a clean repository with the branches, the first one is checked out.
+func New(branches ...string) *Repository {
+	r := &Repository{
+		Local:    slices.Clone(branches),
+		Info:     make(map[string]git.BranchInfo),
+		Remote:   make(map[string][]string),
+		Tracking: make(map[string][]string),
+		Merged:   make(map[string][]string),
+		URLs:     make(map[string]string),
+		Errors:   make(map[string]error),
+	}
+
+	if len(branches) > 0 {
+		r.Current = branches[0]
+	}
+
+	return r
+}
+
+func (r *Repository) Branches() ([]git.BranchInfo, error) {
+	if err := r.Errors["Branches"]; err != nil {
+		return nil, err
+	}
+
+	branches := make([]git.BranchInfo, len(r.Local))
+	for i, name := range slices.Sorted(slices.Values(r.Local)) {
+		b := r.Info[name]
+		b.Name = name
+		b.IsCurrent = name == r.Current
+		if b.SHA == "" {
+			b.SHA, _ = r.ResolveRef(name)
+		}
+
+		branches[i] = b
+	}
+
+	return branches, nil
+}
+
+func (r *Repository) RemoteBranches(remote string) ([]git.BranchInfo, error) {
+	if err := r.Errors["RemoteBranches"]; err != nil {
+		return nil, err
+	}
+
+	branches := make([]git.BranchInfo, len(r.Tracking[remote]))
+	for i, name := range slices.Sorted(slices.Values(r.Tracking[remote])) {
+		branches[i] = git.BranchInfo{Name: fmt.Sprintf("%s/%s", remote, name)}
+	}
+
+	return branches, nil
+}
+
+func (r *Repository) CurrentBranch() (string, error) {
+	if r.Current == "" {
+		return "", errors.New("git: HEAD is detached")
+	}
+
+	return r.Current, nil
+}
+
+func (r *Repository) HasBranch(name string) bool {
+	return slices.Contains(r.Local, name)
+}
+
+func (r *Repository) FindBranch(remote, name string) (git.BranchLocation, error) {
+	if err := r.Errors["FindBranch"]; err != nil {
+		return git.BranchMissing, err
+	}
+
+	switch {
+	case r.HasBranch(name):
+		return git.BranchLocal, nil
+	case slices.Contains(r.Tracking[remote], name):
+		return git.BranchRemoteTracking, nil
+	case slices.Contains(r.Remote[remote], name):
+		return git.BranchRemoteOnly, nil
+	default:
+		return git.BranchMissing, nil
+	}
+}
+
+func (r *Repository) Checkout(name string, create bool) (string, error) {
+	if err := r.Errors["Checkout"]; err != nil {
+		return "", err
+	}
+
+	if create {
+		if r.HasBranch(name) {
+			return "", fmt.Errorf("a branch named %q already exists", name)
+		}
+
+		r.Local = append(r.Local, name)
+		r.record("checkout -b %s", name)
+	} else {
+		if !r.HasBranch(name) {
+			return "", fmt.Errorf("pathspec %q did not match any branch", name)
+		}
+
+		r.record("checkout %s", name)
+	}
+
+	r.Current = name
+	return fmt.Sprintf("Switched to branch '%s'", name), nil
+}
+
+func (r *Repository) CheckoutTracking(remote, name string) (string, error) {
+	if err := r.Errors["CheckoutTracking"]; err != nil {
+		return "", err
+	}
+
+	if !slices.Contains(r.Tracking[remote], name) {
+		return "", fmt.Errorf("%s/%s is not a remote-tracking branch", remote, name)
+	}
+
+	if r.HasBranch(name) {
+		return "", fmt.Errorf("a branch named %q already exists", name)
+	}
+
+	r.Local = append(r.Local, name)
+	r.Current = name
+	r.record("checkout -b %s --track %s/%s", name, remote, name)
+
+	return fmt.Sprintf("branch '%s' set up to track '%s/%s'", name, remote, name), nil
+}
+
+func (r *Repository) IsClean() (bool, error) {
+	if err := r.Errors["IsClean"]; err != nil {
+		return false, err
+	}
+
+	return !r.Dirty, nil
+}
+
+func (r *Repository) IsAncestor(ref, of string) (bool, error) {
+	if err := r.Errors["IsAncestor"]; err != nil {
+		return false, err
+	}
+
+	return ref == of || slices.Contains(r.Merged[ref], of), nil
+}
+
+func (r *Repository) Fetch(remote string, prune bool) (string, error) {
+	if err := r.Errors["Fetch"]; err != nil {
+		return "", err
+	}
+
+	if prune {
+		r.Tracking[remote] = nil
+		r.record("fetch -p %s", remote)
+	} else {
+		r.record("fetch %s", remote)
+	}
+
+	for _, name := range r.Remote[remote] {
+		if !slices.Contains(r.Tracking[remote], name) {
+			r.Tracking[remote] = append(r.Tracking[remote], name)
+		}
+	}
+
+	return "", nil
+}
+
+func (r *Repository) Push(remote, name string) (string, error) {
+	if err := r.Errors["Push"]; err != nil {
+		return "", err
+	}
+
+	if !r.HasBranch(name) {
+		return "", fmt.Errorf("src refspec %s does not match any", name)
+	}
+
+	if !slices.Contains(r.Remote[remote], name) {
+		r.Remote[remote] = append(r.Remote[remote], name)
+	}
+
+	if !slices.Contains(r.Tracking[remote], name) {
+		r.Tracking[remote] = append(r.Tracking[remote], name)
+	}
+
+	info := r.Info[name]
+	info.Upstream = fmt.Sprintf("%s/%s", remote, name)
+	r.Info[name] = info
+
+	r.record("push -u %s %s", remote, name)
+	return "", nil
+}
+
+func (r *Repository) Commit(message string, args ...string) (string, error) {
+	if err := r.Errors["Commit"]; err != nil {
+		return "", err
+	}
+
+	r.Messages = append(r.Messages, message)
+	r.record("%s", strings.Join(append([]string{"commit"}, args...), " "))
+
+	return "", nil
+}
+
+func (r *Repository) RenameBranch(name, newName string) (string, error) {
+	if err := r.Errors["RenameBranch"]; err != nil {
+		return "", err
+	}
+
+	i := slices.Index(r.Local, name)
+	if i == -1 {
+		return "", fmt.Errorf("branch %q not found", name)
+	}
+
+	if r.HasBranch(newName) {
+		return "", fmt.Errorf("a branch named %q already exists", newName)
+	}
+
+	r.Local[i] = newName
+	if info, ok := r.Info[name]; ok {
+		delete(r.Info, name)
+		r.Info[newName] = info
+	}
+
+	if r.Current == name {
+		r.Current = newName
+	}
+
+	r.record("branch -m %s %s", name, newName)
+	return "", nil
+}
+
+func (r *Repository) DeleteBranch(name string) (string, error) {
+	if err := r.Errors["DeleteBranch"]; err != nil {
+		return "", err
+	}
+
+	if name == r.Current {
+		return "", fmt.Errorf("cannot delete branch %q checked out", name)
+	}
+
+	i := slices.Index(r.Local, name)
+	if i == -1 {
+		return "", fmt.Errorf("branch %q not found", name)
+	}
+
+	r.Local = slices.Delete(r.Local, i, i+1)
+	r.record("branch -D %s", name)
+
+	return fmt.Sprintf("Deleted branch %s", name), nil
+}
+
+func (r *Repository) DeleteRemoteBranch(remote, name string) (string, error) {
+	if err := r.Errors["DeleteRemoteBranch"]; err != nil {
+		return "", err
+	}
+
+	i := slices.Index(r.Remote[remote], name)
+	if i == -1 {
+		return "", fmt.Errorf("unable to delete %q: remote ref does not exist", name)
+	}
+
+	r.Remote[remote] = slices.Delete(r.Remote[remote], i, i+1)
+	r.Tracking[remote] = slices.DeleteFunc(r.Tracking[remote], func(b string) bool { return b == name })
+	r.record("push -d %s %s", remote, name)
+
+	return "", nil
+}
+
+// ResolveRef returns a fake SHA derived from the name of a local branch.
+func (r *Repository) ResolveRef(ref string) (string, error) {
+	if !r.HasBranch(ref) {
+		return "", fmt.Errorf("git: unknown revision %q", ref)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(ref))
+
+	return fmt.Sprintf("%040x", h.Sum64()), nil
+}
+
+func (r *Repository) HooksDir() (string, error) {
+	if r.Hooks == "" {
+		return "", errors.New("git: hooks directory is not set")
+	}
+
+	return r.Hooks, nil
+}
+
+func (r *Repository) Root() (string, error) {
+	if r.Dir == "" {
+		return "", errors.New("git: top-level directory is not set")
+	}
+
+	return r.Dir, nil
+}
+
+func (r *Repository) RemoteURL(remote string) (string, error) {
+	url, ok := r.URLs[remote]
+	if !ok {
+		return "", fmt.Errorf("git: remote %q not found", remote)
+	}
+
+	return url, nil
+}
+
+func (r *Repository) record(format string, args ...any) {
+	r.Commands = append(r.Commands, fmt.Sprintf(format, args...))
+}
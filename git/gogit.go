@@ -0,0 +1,544 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"twig/log"
+)
+
+// GoGitRepository implements Repository in pure Go with go-git, so twig works without the git binary.
+// Remotes are reached with the ssh agent or anonymously, git credential helpers are not used.
+type GoGitRepository struct {
+	repo *gogit.Repository
+}
+
+func NewGoGitRepository(dir string) (*GoGitRepository, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("git: open %q: %w", dir, err)
+	}
+
+	return &GoGitRepository{repo: repo}, nil
+}
+
+func (r *GoGitRepository) Branches() ([]BranchInfo, error) {
+	refs, err := r.repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("git: list branches: %w", err)
+	}
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("git: list branches: %w", err)
+	}
+
+	current, _ := r.CurrentBranch()
+	worktrees := r.worktrees()
+
+	branches := make([]BranchInfo, 0)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		commit, err := r.repo.CommitObject(ref.Hash())
+		if err != nil {
+			return err
+		}
+
+		b := BranchInfo{
+			Name:      ref.Name().Short(),
+			Date:      commit.Committer.When,
+			Author:    commit.Author.Name,
+			SHA:       ref.Hash().String(),
+			IsCurrent: ref.Name().Short() == current,
+			Worktree:  worktrees[ref.Name().Short()],
+		}
+
+		if upstream, ok := cfg.Branches[b.Name]; ok && upstream.Merge != "" {
+			if err := r.track(&b, commit, upstream); err != nil {
+				return err
+			}
+		}
+
+		branches = append(branches, b)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("git: list branches: %w", err)
+	}
+
+	slices.SortFunc(branches, func(a, b BranchInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return branches, nil
+}
+
+func (r *GoGitRepository) RemoteBranches(remote string) ([]BranchInfo, error) {
+	refs, err := r.repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("git: list remote branches: %w", err)
+	}
+
+	prefix := fmt.Sprintf("refs/remotes/%s/", remote)
+
+	branches := make([]BranchInfo, 0)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || !strings.HasPrefix(ref.Name().String(), prefix) {
+			return nil
+		}
+
+		commit, err := r.repo.CommitObject(ref.Hash())
+		if err != nil {
+			return err
+		}
+
+		branches = append(branches, BranchInfo{
+			Name:   ref.Name().Short(),
+			Date:   commit.Committer.When,
+			Author: commit.Author.Name,
+			SHA:    ref.Hash().String(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("git: list remote branches: %w", err)
+	}
+
+	slices.SortFunc(branches, func(a, b BranchInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return branches, nil
+}
+
+// track sets the upstream of the branch and counts the commits ahead and behind it.
+func (r *GoGitRepository) track(b *BranchInfo, commit *object.Commit, upstream *config.Branch) error {
+	ref := plumbing.NewRemoteReferenceName(upstream.Remote, upstream.Merge.Short())
+	if upstream.Remote == "." {
+		ref = upstream.Merge
+	}
+	b.Upstream = ref.Short()
+
+	target, err := r.repo.Reference(ref, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		b.Gone = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	other, err := r.repo.CommitObject(target.Hash())
+	if err != nil {
+		return err
+	}
+
+	bases, err := commit.MergeBase(other)
+	if err != nil {
+		return err
+	}
+
+	if b.Ahead, err = countCommits(commit, bases); err != nil {
+		return err
+	}
+
+	b.Behind, err = countCommits(other, bases)
+	return err
+}
+
+// countCommits counts the commits reachable from tip without walking past the bases. Unlike git it may
+// count commits of merged branches which are reachable from the bases as well.
+func countCommits(tip *object.Commit, bases []*object.Commit) (int, error) {
+	ignore := make([]plumbing.Hash, len(bases))
+	for i, base := range bases {
+		ignore[i] = base.Hash
+	}
+
+	n := 0
+	err := object.NewCommitPreorderIter(tip, nil, ignore).ForEach(func(*object.Commit) error {
+		n++
+		return nil
+	})
+
+	return n, err
+}
+
+// worktrees returns the path of the worktree every checked out branch is in, linked worktrees included.
+func (r *GoGitRepository) worktrees() map[string]string {
+	result := make(map[string]string)
+
+	if worktree, err := r.repo.Worktree(); err == nil {
+		if current, err := r.CurrentBranch(); err == nil {
+			result[current] = worktree.Filesystem.Root()
+		}
+	}
+
+	storage, ok := r.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return result
+	}
+
+	dir := filepath.Join(storage.Filesystem().Root(), "worktrees")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result
+	}
+
+	for _, entry := range entries {
+		head, err := os.ReadFile(filepath.Join(dir, entry.Name(), "HEAD"))
+		if err != nil {
+			continue
+		}
+
+		gitdir, err := os.ReadFile(filepath.Join(dir, entry.Name(), "gitdir"))
+		if err != nil {
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: refs/heads/"); ok {
+			result[name] = filepath.Dir(strings.TrimSpace(string(gitdir)))
+		}
+	}
+
+	return result
+}
+
+func (r *GoGitRepository) CurrentBranch() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("git: current branch: %w", err)
+	}
+
+	if !head.Name().IsBranch() {
+		return "", errors.New("git: HEAD is detached")
+	}
+
+	return head.Name().Short(), nil
+}
+
+func (r *GoGitRepository) HasBranch(name string) bool {
+	_, err := r.repo.Reference(plumbing.NewBranchReferenceName(name), false)
+	return err == nil
+}
+
+func (r *GoGitRepository) FindBranch(remote, name string) (BranchLocation, error) {
+	if r.HasBranch(name) {
+		return BranchLocal, nil
+	}
+
+	if _, err := r.repo.Reference(plumbing.NewRemoteReferenceName(remote, name), false); err == nil {
+		return BranchRemoteTracking, nil
+	}
+
+	rem, err := r.repo.Remote(remote)
+	if err != nil {
+		return BranchMissing, fmt.Errorf("git: remote %q: %w", remote, err)
+	}
+
+	refs, err := rem.List(&gogit.ListOptions{})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return BranchMissing, nil
+	}
+	if err != nil {
+		log.Warn().Println(fmt.Sprintf("git: ls-remote %q: %s, assuming %q is not on the remote", remote, err, name))
+		return BranchMissing, nil
+	}
+
+	for _, ref := range refs {
+		if ref.Name() == plumbing.NewBranchReferenceName(name) {
+			return BranchRemoteOnly, nil
+		}
+	}
+
+	return BranchMissing, nil
+}
+
+func (r *GoGitRepository) Checkout(name string, create bool) (string, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	err = worktree.Checkout(&gogit.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(name),
+		Create: create,
+		Keep:   true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("git: checkout %q: %w", name, err)
+	}
+
+	return "", nil
+}
+
+func (r *GoGitRepository) CheckoutTracking(remote, name string) (string, error) {
+	ref, err := r.repo.Reference(plumbing.NewRemoteReferenceName(remote, name), true)
+	if err != nil {
+		return "", fmt.Errorf("git: remote branch %s/%s: %w", remote, name, err)
+	}
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	err = worktree.Checkout(&gogit.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(name),
+		Hash:   ref.Hash(),
+		Create: true,
+		Keep:   true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("git: checkout %q: %w", name, err)
+	}
+
+	err = r.repo.CreateBranch(&config.Branch{Name: name, Remote: remote, Merge: plumbing.NewBranchReferenceName(name)})
+	if err != nil && !errors.Is(err, gogit.ErrBranchExists) {
+		return "", fmt.Errorf("git: track %s/%s: %w", remote, name, err)
+	}
+
+	return "", nil
+}
+
+func (r *GoGitRepository) IsClean() (bool, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return false, fmt.Errorf("git: status: %w", err)
+	}
+
+	return status.IsClean(), nil
+}
+
+func (r *GoGitRepository) IsAncestor(ref, of string) (bool, error) {
+	commit, err := r.commit(ref)
+	if err != nil {
+		return false, err
+	}
+
+	other, err := r.commit(of)
+	if err != nil {
+		return false, err
+	}
+
+	return commit.IsAncestor(other)
+}
+
+func (r *GoGitRepository) Fetch(remote string, prune bool) (string, error) {
+	var out bytes.Buffer
+
+	err := r.repo.Fetch(&gogit.FetchOptions{RemoteName: remote, Prune: prune, Progress: &out})
+	if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		err = nil
+	}
+
+	return out.String(), err
+}
+
+func (r *GoGitRepository) Push(remote, name string) (string, error) {
+	ref := plumbing.NewBranchReferenceName(name)
+
+	out, err := r.push(remote, config.RefSpec(fmt.Sprintf("%s:%s", ref, ref)))
+	if err != nil {
+		return out, err
+	}
+
+	// like push -u, the upstream is replaced even if the branch tracked another one
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return out, err
+	}
+
+	upstream, ok := cfg.Branches[name]
+	if !ok {
+		upstream = &config.Branch{Name: name}
+		cfg.Branches[name] = upstream
+	}
+	upstream.Remote, upstream.Merge = remote, ref
+
+	return out, r.repo.Storer.SetConfig(cfg)
+}
+
+// Commit supports no options of git commit and runs no hooks.
+func (r *GoGitRepository) Commit(message string, args ...string) (string, error) {
+	if len(args) > 0 {
+		return "", fmt.Errorf("git: commit options %q need the %s backend", args, BackendExec)
+	}
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := worktree.Commit(message, &gogit.CommitOptions{})
+	if err != nil {
+		return "", fmt.Errorf("git: commit: %w", err)
+	}
+
+	return fmt.Sprintf("[%s] %s", hash.String()[:7], strings.SplitN(message, "\n", 2)[0]), nil
+}
+
+func (r *GoGitRepository) RenameBranch(name, newName string) (string, error) {
+	ref, err := r.repo.Reference(plumbing.NewBranchReferenceName(name), false)
+	if err != nil {
+		return "", fmt.Errorf("git: rename branch %q: %w", name, err)
+	}
+
+	if r.HasBranch(newName) {
+		return "", fmt.Errorf("git: rename branch %q: a branch named %q already exists", name, newName)
+	}
+
+	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(newName), ref.Hash())
+	if err := r.repo.Storer.SetReference(newRef); err != nil {
+		return "", fmt.Errorf("git: rename branch %q: %w", name, err)
+	}
+
+	head, err := r.repo.Storer.Reference(plumbing.HEAD)
+	if err == nil && head.Type() == plumbing.SymbolicReference && head.Target() == ref.Name() {
+		if err := r.repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, newRef.Name())); err != nil {
+			return "", fmt.Errorf("git: rename branch %q: %w", name, err)
+		}
+	}
+
+	if err := r.repo.Storer.RemoveReference(ref.Name()); err != nil {
+		return "", fmt.Errorf("git: rename branch %q: %w", name, err)
+	}
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return "", err
+	}
+
+	if upstream, ok := cfg.Branches[name]; ok {
+		delete(cfg.Branches, name)
+		upstream.Name = newName
+		cfg.Branches[newName] = upstream
+
+		if err := r.repo.Storer.SetConfig(cfg); err != nil {
+			return "", fmt.Errorf("git: rename branch %q: %w", name, err)
+		}
+	}
+
+	return "", nil
+}
+
+func (r *GoGitRepository) DeleteBranch(name string) (string, error) {
+	// like git branch -D, a branch checked out in any worktree stays
+	if path, ok := r.worktrees()[name]; ok {
+		return "", fmt.Errorf("git: cannot delete branch %q checked out at %q", name, path)
+	}
+
+	if err := r.repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(name)); err != nil {
+		return "", fmt.Errorf("git: delete branch %q: %w", name, err)
+	}
+
+	if err := r.repo.DeleteBranch(name); err != nil && !errors.Is(err, gogit.ErrBranchNotFound) {
+		return "", fmt.Errorf("git: delete branch %q: %w", name, err)
+	}
+
+	return "", nil
+}
+
+func (r *GoGitRepository) DeleteRemoteBranch(remote, name string) (string, error) {
+	return r.push(remote, config.RefSpec(":"+plumbing.NewBranchReferenceName(name)))
+}
+
+func (r *GoGitRepository) ResolveRef(ref string) (string, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", fmt.Errorf("git: unknown revision %q", ref)
+	}
+
+	return hash.String(), nil
+}
+
+func (r *GoGitRepository) HooksDir() (string, error) {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("git: hooks directory: %w", err)
+	}
+
+	storage, ok := r.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", errors.New("git: hooks directory: repository is not on disk")
+	}
+
+	hooksPath := cfg.Raw.Section("core").Option("hooksPath")
+	if hooksPath == "" {
+		return filepath.Join(storage.Filesystem().Root(), "hooks"), nil
+	}
+
+	if strings.HasPrefix(hooksPath, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("git: hooks directory: %w", err)
+		}
+		hooksPath = filepath.Join(home, hooksPath[2:])
+	}
+
+	// a relative core.hooksPath is relative to the worktree, hooks run there
+	if !filepath.IsAbs(hooksPath) {
+		worktree, err := r.repo.Worktree()
+		if err != nil {
+			return "", fmt.Errorf("git: hooks directory: %w", err)
+		}
+		hooksPath = filepath.Join(worktree.Filesystem.Root(), hooksPath)
+	}
+
+	return hooksPath, nil
+}
+
+func (r *GoGitRepository) Root() (string, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("git: top-level directory: %w", err)
+	}
+
+	return worktree.Filesystem.Root(), nil
+}
+
+func (r *GoGitRepository) RemoteURL(remote string) (string, error) {
+	rm, err := r.repo.Remote(remote)
+	if err != nil || len(rm.Config().URLs) == 0 {
+		return "", fmt.Errorf("git: remote %q not found", remote)
+	}
+
+	return rm.Config().URLs[0], nil
+}
+
+func (r *GoGitRepository) push(remote string, spec config.RefSpec) (string, error) {
+	var out bytes.Buffer
+
+	err := r.repo.Push(&gogit.PushOptions{RemoteName: remote, RefSpecs: []config.RefSpec{spec}, Progress: &out})
+	if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		err = nil
+	}
+
+	return out.String(), err
+}
+
+func (r *GoGitRepository) commit(ref string) (*object.Commit, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("git: unknown revision %q", ref)
+	}
+
+	return r.repo.CommitObject(*hash)
+}
@@ -0,0 +1,280 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"twig/log"
+)
+
+const (
+	BackendExec  = "exec"   // runs the git binary
+	BackendGoGit = "go-git" // pure Go, no git binary needed
+)
+
+// Repository is the part of git twig works with. Commands use it instead of running git directly,
+// so they can be tested against the in-memory fake of package gittest.
+// Methods changing the repository return the output of git, which is empty for backends without any.
+type Repository interface {
+	// Branches returns the local branches sorted by name.
+	Branches() ([]BranchInfo, error)
+	// RemoteBranches returns the remote-tracking branches of the remote sorted by name, e.g. origin/main.
+	RemoteBranches(remote string) ([]BranchInfo, error)
+	// CurrentBranch returns the checked out branch, an error for a detached HEAD.
+	CurrentBranch() (string, error)
+	// HasBranch reports whether the local branch exists.
+	HasBranch(name string) bool
+	// FindBranch looks for the branch locally, among the remote-tracking branches of the remote and
+	// finally on the remote itself. An unreachable remote is logged and reported as BranchMissing.
+	FindBranch(remote, name string) (BranchLocation, error)
+	// Checkout switches to the branch, create starts a new branch from HEAD.
+	Checkout(name string, create bool) (string, error)
+	// CheckoutTracking creates the branch from its remote-tracking branch and sets it as the upstream.
+	CheckoutTracking(remote, name string) (string, error)
+	// IsClean reports whether the worktree has neither changes nor untracked files.
+	IsClean() (bool, error)
+	// IsAncestor reports whether ref is reachable from of, i.e. ref is merged into of.
+	IsAncestor(ref, of string) (bool, error)
+	// Fetch updates the remote-tracking branches of the remote, prune removes the stale ones.
+	Fetch(remote string, prune bool) (string, error)
+	// Push pushes the branch and sets the remote branch as its upstream.
+	Push(remote, name string) (string, error)
+	// Commit records the staged changes with the message, args are further options of git commit.
+	// Interactive backends run git on the terminal and return no output.
+	Commit(message string, args ...string) (string, error)
+	// RenameBranch renames the local branch along with its config, the upstream stays the same.
+	RenameBranch(name, newName string) (string, error)
+	DeleteBranch(name string) (string, error)
+	DeleteRemoteBranch(remote, name string) (string, error)
+	// ResolveRef returns the commit SHA of a branch, tag or any other revision.
+	ResolveRef(ref string) (string, error)
+	// HooksDir returns the directory git runs hooks from, core.hooksPath included.
+	HooksDir() (string, error)
+	// Root returns the top-level directory of the worktree.
+	Root() (string, error)
+	// RemoteURL returns the fetch URL of the remote.
+	RemoteURL(remote string) (string, error)
+}
+
+var (
+	_ Repository = (*ExecRepository)(nil)
+	_ Repository = (*GoGitRepository)(nil)
+)
+
+// Open returns the repository containing dir, an empty dir is the working directory.
+func Open(backend, dir string) (Repository, error) {
+	switch backend {
+	case BackendExec, "":
+		return NewExecRepository(dir), nil
+	case BackendGoGit:
+		return NewGoGitRepository(dir)
+	default:
+		return nil, fmt.Errorf("git: unknown backend %q", backend)
+	}
+}
+
+// ExecRepository runs the git binary, so it behaves exactly like git on the command line.
+// Only porcelain and --format output is parsed, which does not depend on the git version or locale.
+type ExecRepository struct {
+	dir string
+}
+
+func NewExecRepository(dir string) *ExecRepository {
+	return &ExecRepository{dir: dir}
+}
+
+func (r *ExecRepository) Branches() ([]BranchInfo, error) {
+	out, err := Command(ForEachRef, branchFormat(), "refs/heads").Untranslated().In(r.dir).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git: list branches: %w", err)
+	}
+
+	return parseBranches(out)
+}
+
+func (r *ExecRepository) RemoteBranches(remote string) ([]BranchInfo, error) {
+	out, err := Command(ForEachRef, branchFormat(), fmt.Sprintf("refs/remotes/%s", remote)).Untranslated().In(r.dir).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git: list remote branches: %w", err)
+	}
+
+	branches, err := parseBranches(out)
+	if err != nil {
+		return nil, err
+	}
+
+	// refs/remotes/<remote>/HEAD is shortened to the name of the remote
+	return slices.DeleteFunc(branches, func(b BranchInfo) bool {
+		return b.Name == remote || b.Name == remote+"/HEAD"
+	}), nil
+}
+
+func (r *ExecRepository) CurrentBranch() (string, error) {
+	out, err := Command(RevParse, "--abbrev-ref", "HEAD").In(r.dir).Output()
+	if err != nil {
+		return "", fmt.Errorf("git: current branch: %w", err)
+	}
+
+	name := strings.TrimSpace(string(out))
+	if name == "HEAD" {
+		return "", errors.New("git: HEAD is detached")
+	}
+
+	return name, nil
+}
+
+func (r *ExecRepository) HasBranch(name string) bool {
+	return r.hasRef("refs/heads/" + name)
+}
+
+func (r *ExecRepository) FindBranch(remote, name string) (BranchLocation, error) {
+	if r.HasBranch(name) {
+		return BranchLocal, nil
+	}
+
+	if r.hasRef(fmt.Sprintf("refs/remotes/%s/%s", remote, name)) {
+		return BranchRemoteTracking, nil
+	}
+
+	err := Command(LsRemote, "--exit-code", "--heads", remote, "refs/heads/"+name).In(r.dir).Run()
+
+	// ls-remote exits with 2 when no ref matches
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+		return BranchMissing, nil
+	}
+
+	// an unreachable remote must not stop e.g. creating the branch locally
+	if err != nil {
+		log.Warn().Println(fmt.Sprintf("git: ls-remote %q: %s, assuming %q is not on the remote", remote, err, name))
+		return BranchMissing, nil
+	}
+
+	return BranchRemoteOnly, nil
+}
+
+func (r *ExecRepository) hasRef(ref string) bool {
+	return Command(ShowRef, "--verify", "--quiet", ref).In(r.dir).Run() == nil
+}
+
+func (r *ExecRepository) Checkout(name string, create bool) (string, error) {
+	args := []string{name}
+	if create {
+		args = append([]string{"-b"}, args...)
+	}
+
+	out, err := Command(Checkout, args...).In(r.dir).CombinedOutput()
+	return string(out), err
+}
+
+func (r *ExecRepository) CheckoutTracking(remote, name string) (string, error) {
+	out, err := Command(Checkout, "-b", name, "--track", fmt.Sprintf("%s/%s", remote, name)).In(r.dir).CombinedOutput()
+	return string(out), err
+}
+
+func (r *ExecRepository) IsClean() (bool, error) {
+	out, err := Command(Status, "--porcelain").In(r.dir).Output()
+	if err != nil {
+		return false, fmt.Errorf("git: status: %w", err)
+	}
+
+	return len(lines(out)) == 0, nil
+}
+
+func (r *ExecRepository) IsAncestor(ref, of string) (bool, error) {
+	err := Command(MergeBase, "--is-ancestor", ref, of).In(r.dir).Run()
+
+	// merge-base exits with 1 when ref is not an ancestor and with more on errors
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("git: merge-base %q %q: %w", ref, of, err)
+	}
+
+	return true, nil
+}
+
+func (r *ExecRepository) Fetch(remote string, prune bool) (string, error) {
+	args := []string{remote}
+	if prune {
+		args = append([]string{"-p"}, args...)
+	}
+
+	out, err := Command(Fetch, args...).In(r.dir).CombinedOutput()
+	return string(out), err
+}
+
+func (r *ExecRepository) Push(remote, name string) (string, error) {
+	out, err := Command(Push, "-u", remote, name).In(r.dir).CombinedOutput()
+	return string(out), err
+}
+
+func (r *ExecRepository) Commit(message string, args ...string) (string, error) {
+	return "", Command(Commit, append([]string{"-m", message}, args...)...).In(r.dir).Interactive().Run()
+}
+
+func (r *ExecRepository) RenameBranch(name, newName string) (string, error) {
+	out, err := Command(Branch, "-m", name, newName).In(r.dir).CombinedOutput()
+	return string(out), err
+}
+
+func (r *ExecRepository) DeleteBranch(name string) (string, error) {
+	out, err := Command(Branch, "-D", name).In(r.dir).CombinedOutput()
+	return string(out), err
+}
+
+func (r *ExecRepository) DeleteRemoteBranch(remote, name string) (string, error) {
+	out, err := Command(Push, "-d", remote, name).In(r.dir).CombinedOutput()
+	return string(out), err
+}
+
+func (r *ExecRepository) ResolveRef(ref string) (string, error) {
+	out, err := Command(RevParse, "--verify", "--quiet", ref+"^{commit}").In(r.dir).Output()
+	if err != nil {
+		return "", fmt.Errorf("git: unknown revision %q", ref)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *ExecRepository) HooksDir() (string, error) {
+	out, err := Command(RevParse, "--path-format=absolute", "--git-path", "hooks").In(r.dir).Output()
+	if err != nil {
+		return "", fmt.Errorf("git: hooks directory: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *ExecRepository) Root() (string, error) {
+	out, err := Command(RevParse, "--show-toplevel").In(r.dir).Output()
+	if err != nil {
+		return "", fmt.Errorf("git: top-level directory: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *ExecRepository) RemoteURL(remote string) (string, error) {
+	out, err := Command(Remote, "get-url", remote).In(r.dir).Output()
+	if err != nil {
+		return "", fmt.Errorf("git: remote %q not found", remote)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func lines(out []byte) []string {
+	result := make([]string, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
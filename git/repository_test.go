@@ -0,0 +1,409 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"twig/log"
+)
+
+func init() {
+	log.CreateNoOpTestRecorders()
+}
+
+// newTestRepository creates a repository with the branches development and feature, feature is one commit ahead.
+// The repository is cloned from a bare origin, which is one commit behind feature.
+// feature is checked out in the linked worktree "worktree" next to the repository.
+func newTestRepository(t *testing.T) string {
+	if err := exec.Command("git", "version").Run(); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "twig")
+	t.Setenv("GIT_AUTHOR_EMAIL", "twig@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "twig")
+	t.Setenv("GIT_COMMITTER_EMAIL", "twig@example.com")
+
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+	dir := filepath.Join(root, "repo")
+
+	run := func(dir string, args ...string) {
+		runGit(t, dir, args...)
+	}
+
+	run(root, "init", "--bare", "-b", "development", origin)
+	run(root, "clone", origin, dir)
+	run(dir, "config", "user.name", "twig")
+	run(dir, "config", "user.email", "twig@example.com")
+	run(dir, "commit", "--allow-empty", "-m", "initial")
+	run(dir, "checkout", "-b", "feature")
+	run(dir, "commit", "--allow-empty", "-m", "feature")
+	run(dir, "push", "-u", "origin", "development", "feature")
+	run(dir, "commit", "--allow-empty", "-m", "unpushed")
+	run(dir, "checkout", "development")
+	run(dir, "worktree", "add", filepath.Join(root, "worktree"), "feature")
+
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	command := exec.Command("git", args...)
+	command.Dir = dir
+	if out, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("git %q: %s", args, out)
+	}
+}
+
+// pushRemoteBranches creates "tracked" on origin along with its remote-tracking branch and "untracked"
+// only on origin, pushed by its path instead of the remote name.
+func pushRemoteBranches(t *testing.T, dir string) {
+	runGit(t, dir, "push", "origin", "development:refs/heads/tracked")
+	runGit(t, dir, "push", filepath.Join(filepath.Dir(dir), "origin.git"), "development:refs/heads/untracked")
+}
+
+func openBackends(t *testing.T, dir string) map[string]Repository {
+	repos := make(map[string]Repository)
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := Open(backend, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repos[backend] = repo
+	}
+
+	return repos
+}
+
+func TestRepositoryBranches(t *testing.T) {
+	dir := newTestRepository(t)
+
+	for backend, repo := range openBackends(t, dir) {
+		want := []BranchInfo{
+			{Name: "development", Upstream: "origin/development", IsCurrent: true, Worktree: dir},
+			{Name: "feature", Upstream: "origin/feature", Ahead: 1, Worktree: filepath.Join(filepath.Dir(dir), "worktree")},
+		}
+
+		subject, err := repo.Branches()
+		if err != nil || len(subject) != len(want) {
+			t.Fatalf(`%s Branches() = %v %v, want %d branches`, backend, subject, err, len(want))
+		}
+
+		for i, b := range subject {
+			if b.Author != "twig" || len(b.SHA) != 40 || b.Date.IsZero() {
+				t.Errorf(`%s Branches()[%d] = %+v, want author, SHA and date`, backend, i, b)
+			}
+
+			b.Author, b.SHA, b.Date = "", "", time.Time{}
+			if b != want[i] {
+				t.Errorf(`%s Branches()[%d] = %+v, want match for %+v`, backend, i, b, want[i])
+			}
+		}
+
+		if current, err := repo.CurrentBranch(); err != nil || current != "development" {
+			t.Errorf(`%s CurrentBranch() = %q %v, want match for "development"`, backend, current, err)
+		}
+
+		if !repo.HasBranch("feature") || repo.HasBranch("missing") {
+			t.Errorf(`%s HasBranch() detects "missing" or misses "feature"`, backend)
+		}
+	}
+}
+
+func TestRepositoryFindBranch(t *testing.T) {
+	dir := newTestRepository(t)
+	pushRemoteBranches(t, dir)
+
+	want := map[string]BranchLocation{
+		"feature":   BranchLocal,
+		"tracked":   BranchRemoteTracking,
+		"untracked": BranchRemoteOnly,
+		"missing":   BranchMissing,
+	}
+
+	for backend, repo := range openBackends(t, dir) {
+		for name, location := range want {
+			if subject, err := repo.FindBranch("origin", name); subject != location || err != nil {
+				t.Errorf(`%s FindBranch(origin, %s) = %s %v, want match for %s`, backend, name, subject, err, location)
+			}
+		}
+	}
+}
+
+func TestRepositoryRemoteBranches(t *testing.T) {
+	dir := newTestRepository(t)
+	pushRemoteBranches(t, dir)
+
+	want := []string{"origin/development", "origin/feature", "origin/tracked"}
+
+	for backend, repo := range openBackends(t, dir) {
+		subject, err := repo.RemoteBranches("origin")
+		if err != nil {
+			t.Fatalf(`%s RemoteBranches(origin) = %v`, backend, err)
+		}
+
+		names := make([]string, len(subject))
+		for i, b := range subject {
+			names[i] = b.Name
+		}
+
+		if !slices.Equal(names, want) || subject[0].SHA == "" || subject[0].IsCurrent {
+			t.Errorf(`%s RemoteBranches(origin) = %+v, want match for %q`, backend, subject, want)
+		}
+	}
+}
+
+func TestRepositoryCheckoutTracking(t *testing.T) {
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		dir := newTestRepository(t)
+		pushRemoteBranches(t, dir)
+
+		repo, err := Open(backend, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := repo.CheckoutTracking("origin", "tracked"); err != nil {
+			t.Fatalf(`%s CheckoutTracking(origin, tracked) = %v`, backend, err)
+		}
+
+		branches, err := repo.Branches()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		i := slices.IndexFunc(branches, func(b BranchInfo) bool { return b.Name == "tracked" })
+		if i == -1 || !branches[i].IsCurrent || branches[i].Upstream != "origin/tracked" {
+			t.Errorf(`%s Branches() = %+v, want current "tracked" with upstream "origin/tracked"`, backend, branches)
+		}
+	}
+}
+
+func TestRepositoryRefs(t *testing.T) {
+	repos := openBackends(t, newTestRepository(t))
+
+	want, err := repos[BackendExec].ResolveRef("feature")
+	if err != nil || len(want) != 40 {
+		t.Fatalf(`exec ResolveRef(feature) = %q %v, want a SHA`, want, err)
+	}
+
+	for backend, repo := range repos {
+		if subject, err := repo.ResolveRef("feature"); subject != want {
+			t.Errorf(`%s ResolveRef(feature) = %q %v, want match for %q`, backend, subject, err, want)
+		}
+
+		if merged, err := repo.IsAncestor("development", "feature"); !merged || err != nil {
+			t.Errorf(`%s IsAncestor(development, feature) = %t %v, want true`, backend, merged, err)
+		}
+
+		if merged, err := repo.IsAncestor("feature", "development"); merged || err != nil {
+			t.Errorf(`%s IsAncestor(feature, development) = %t %v, want false`, backend, merged, err)
+		}
+	}
+}
+
+func TestRepositoryIsClean(t *testing.T) {
+	dir := newTestRepository(t)
+	repos := openBackends(t, dir)
+
+	for backend, repo := range repos {
+		if clean, err := repo.IsClean(); !clean || err != nil {
+			t.Errorf(`%s IsClean() = %t %v, want true`, backend, clean, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "untracked"), []byte("twig"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for backend, repo := range repos {
+		if clean, err := repo.IsClean(); clean || err != nil {
+			t.Errorf(`%s IsClean() = %t %v, want false`, backend, clean, err)
+		}
+	}
+}
+
+func TestRepositoryChanges(t *testing.T) {
+	for backend, repo := range openBackends(t, newTestRepository(t)) {
+		if _, err := repo.Checkout("topic", true); err != nil {
+			t.Fatalf(`%s Checkout(topic, true) = %v`, backend, err)
+		}
+
+		if current, _ := repo.CurrentBranch(); current != "topic" {
+			t.Errorf(`%s CurrentBranch() = %q, want match for "topic"`, backend, current)
+		}
+
+		if _, err := repo.Push("origin", "topic"); err != nil {
+			t.Errorf(`%s Push(origin, topic) = %v`, backend, err)
+		}
+
+		if _, err := repo.DeleteRemoteBranch("origin", "topic"); err != nil {
+			t.Errorf(`%s DeleteRemoteBranch(origin, topic) = %v`, backend, err)
+		}
+
+		if _, err := repo.Fetch("origin", true); err != nil {
+			t.Errorf(`%s Fetch(origin, true) = %v`, backend, err)
+		}
+
+		if _, err := repo.Checkout("development", false); err != nil {
+			t.Fatalf(`%s Checkout(development, false) = %v`, backend, err)
+		}
+
+		if _, err := repo.DeleteBranch("topic"); err != nil || repo.HasBranch("topic") {
+			t.Errorf(`%s DeleteBranch(topic) = %v, want the branch deleted`, backend, err)
+		}
+	}
+}
+
+func TestRepositoryDeleteCheckedOutBranch(t *testing.T) {
+	for backend, repo := range openBackends(t, newTestRepository(t)) {
+		// development is checked out in the repository, feature in the linked worktree
+		for _, name := range []string{"development", "feature"} {
+			if _, err := repo.DeleteBranch(name); err == nil || !repo.HasBranch(name) {
+				t.Errorf(`%s DeleteBranch(%s) = %v, want error for a checked out branch`, backend, name, err)
+			}
+		}
+	}
+}
+
+func TestRepositoryCommit(t *testing.T) {
+	dir := newTestRepository(t)
+
+	for backend, repo := range openBackends(t, dir) {
+		if err := os.WriteFile(filepath.Join(dir, backend), []byte("twig"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", backend)
+
+		message := fmt.Sprintf("fix(ABC-1): commit with %s\n\nRefs: ABC-1", backend)
+		if _, err := repo.Commit(message); err != nil {
+			t.Fatalf(`%s Commit() = %v`, backend, err)
+		}
+
+		out, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%B").Output()
+		if err != nil || strings.TrimSpace(string(out)) != message {
+			t.Errorf(`%s Commit() = %q %v, want match for %q`, backend, out, err, message)
+		}
+	}
+
+	if _, err := openBackends(t, dir)[BackendGoGit].Commit("fix(ABC-1): amend", "--amend"); err == nil {
+		t.Errorf(`go-git Commit(--amend) = nil, want error for unsupported options`)
+	}
+}
+
+func TestRepositoryRenameBranch(t *testing.T) {
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := Open(backend, newTestRepository(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := repo.RenameBranch("development", "main"); err != nil {
+			t.Fatalf(`%s RenameBranch(development, main) = %v`, backend, err)
+		}
+
+		if current, _ := repo.CurrentBranch(); current != "main" || repo.HasBranch("development") {
+			t.Errorf(`%s CurrentBranch() = %q, want match for "main" without "development"`, backend, current)
+		}
+
+		branches, err := repo.Branches()
+		if err != nil || len(branches) != 2 || branches[1].Name != "main" || branches[1].Upstream != "origin/development" {
+			t.Errorf(`%s Branches() = %+v %v, want "main" with upstream "origin/development"`, backend, branches, err)
+		}
+
+		if _, err := repo.Push("origin", "main"); err != nil {
+			t.Fatalf(`%s Push(origin, main) = %v`, backend, err)
+		}
+
+		branches, err = repo.Branches()
+		if err != nil || branches[1].Upstream != "origin/main" {
+			t.Errorf(`%s Branches() = %+v %v, want "main" with upstream "origin/main"`, backend, branches, err)
+		}
+	}
+}
+
+func TestRepositoryHooksDir(t *testing.T) {
+	dir := newTestRepository(t)
+
+	for backend, repo := range openBackends(t, dir) {
+		if subject, err := repo.HooksDir(); subject != filepath.Join(dir, ".git", "hooks") || err != nil {
+			t.Errorf(`%s HooksDir() = %q %v, want hooks of .git`, backend, subject, err)
+		}
+	}
+
+	runGit(t, dir, "config", "core.hooksPath", ".githooks")
+
+	for backend, repo := range openBackends(t, dir) {
+		if subject, err := repo.HooksDir(); subject != filepath.Join(dir, ".githooks") || err != nil {
+			t.Errorf(`%s HooksDir() = %q %v, want .githooks of core.hooksPath`, backend, subject, err)
+		}
+	}
+}
+
+func TestRepositoryRemoteURL(t *testing.T) {
+	dir := newTestRepository(t)
+	runGit(t, dir, "remote", "set-url", "origin", "git@github.com:acme/twig.git")
+
+	for backend, repo := range openBackends(t, dir) {
+		if subject, err := repo.RemoteURL("origin"); subject != "git@github.com:acme/twig.git" || err != nil {
+			t.Errorf(`%s RemoteURL(origin) = %q %v, want the URL of origin`, backend, subject, err)
+		}
+
+		if subject, err := repo.RemoteURL("upstream"); err == nil {
+			t.Errorf(`%s RemoteURL(upstream) = %q, want error for a missing remote`, backend, subject)
+		}
+	}
+}
+
+func TestRepositoryRoot(t *testing.T) {
+	dir := newTestRepository(t)
+
+	for backend, repo := range openBackends(t, dir) {
+		if subject, err := repo.Root(); subject != dir || err != nil {
+			t.Errorf(`%s Root() = %q %v, want match for %q`, backend, subject, err, dir)
+		}
+	}
+}
+
+func TestParseBranches(t *testing.T) {
+	out := "main\x00origin/main\x00behind 2\x002024-05-01T10:00:00+02:00\x00Jane Roe\x00abc\x00*\x00/src/twig\n" +
+		"old\x00origin/old\x00gone\x002024-04-01T10:00:00Z\x00John Doe\x00def\x00 \x00\n"
+
+	subject, err := parseBranches([]byte(out))
+	if err != nil || len(subject) != 2 {
+		t.Fatalf(`parseBranches() = %v %v, want 2 branches`, subject, err)
+	}
+
+	if b := subject[0]; b.Behind != 2 || b.Ahead != 0 || !b.IsCurrent || b.Worktree != "/src/twig" || b.CheckedOutElsewhere() {
+		t.Errorf(`parseBranches()[0] = %+v, want current main behind 2`, b)
+	}
+
+	if b := subject[1]; !b.Gone || b.IsCurrent || b.Author != "John Doe" || b.Worktree != "" {
+		t.Errorf(`parseBranches()[1] = %+v, want old with gone upstream`, b)
+	}
+}
+
+func TestParseBranchesTrack(t *testing.T) {
+	out := "topic\x00origin/topic\x00weird 1\x002024-05-01T10:00:00Z\x00Jane Roe\x00abc\x00 \x00\n"
+
+	if subject, err := parseBranches([]byte(out)); err == nil {
+		t.Errorf(`parseBranches() = %v, want error for unexpected track`, subject)
+	}
+}
+
+func TestRepositoryFindBranchUnreachableRemote(t *testing.T) {
+	dir := newTestRepository(t)
+	runGit(t, dir, "remote", "add", "broken", filepath.Join(t.TempDir(), "missing.git"))
+
+	for backend, repo := range openBackends(t, dir) {
+		if subject, err := repo.FindBranch("broken", "untracked"); subject != BranchMissing || err != nil {
+			t.Errorf(`%s FindBranch(broken, untracked) = %s %v, want match for %s`, backend, subject, err, BranchMissing)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package hosting
+
+import (
+    "fmt"
+    "net/http"
+    "strings"
+)
+
+const bitbucketApi = "https://api.bitbucket.org/2.0"
+
+type bitbucket struct {
+    restClient
+    repo Repository
+}
+
+// bitbucketAuth sends app passwords given as username:password with basic auth, access tokens as bearer.
+func bitbucketAuth(token string) func(request *http.Request) {
+    if username, password, ok := strings.Cut(token, ":"); ok {
+        return func(request *http.Request) {
+            request.SetBasicAuth(username, password)
+        }
+    }
+
+    return bearer(token)
+}
+
+type bitbucketBranch struct {
+    Branch struct {
+        Name string `json:"name"`
+    } `json:"branch"`
+}
+
+type bitbucketUser struct {
+    Uuid      string `json:"uuid,omitempty"`
+    AccountId string `json:"account_id,omitempty"`
+}
+
+type bitbucketPullRequest struct {
+    Title       string          `json:"title"`
+    Description string          `json:"description"`
+    Source      bitbucketBranch `json:"source"`
+    Destination bitbucketBranch `json:"destination"`
+    Reviewers   []bitbucketUser `json:"reviewers,omitempty"`
+}
+
+func (b *bitbucket) Open(pr PullRequest) (string, error) {
+    body := bitbucketPullRequest{Title: pr.Title, Description: pr.Body}
+    body.Source.Branch.Name = pr.Head
+    body.Destination.Branch.Name = pr.Base
+
+    // the API identifies users by {uuid} or account id only
+    for _, reviewer := range pr.Reviewers {
+        if strings.HasPrefix(reviewer, "{") {
+            body.Reviewers = append(body.Reviewers, bitbucketUser{Uuid: reviewer})
+        } else {
+            body.Reviewers = append(body.Reviewers, bitbucketUser{AccountId: reviewer})
+        }
+    }
+
+    var created struct {
+        Links struct {
+            Html struct {
+                Href string `json:"href"`
+            } `json:"html"`
+        } `json:"links"`
+    }
+
+    path := fmt.Sprintf("/repositories/%s/pullrequests", b.repo.Path)
+    if err := b.send(http.MethodPost, path, body, &created); err != nil {
+        return "", fmt.Errorf("bitbucket: %w", err)
+    }
+
+    return created.Links.Html.Href, nil
+}
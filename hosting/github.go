@@ -0,0 +1,68 @@
+package hosting
+
+import (
+    "fmt"
+    "net/http"
+    "strings"
+)
+
+type gitHub struct {
+    restClient
+    repo Repository
+}
+
+// gitHubApi returns the API of github.com or of a GitHub Enterprise Server.
+func gitHubApi(host string) string {
+    if host == "github.com" {
+        return "https://api.github.com"
+    }
+
+    return fmt.Sprintf("https://%s/api/v3", host)
+}
+
+type gitHubPullRequest struct {
+    Title string `json:"title"`
+    Body  string `json:"body"`
+    Head  string `json:"head"`
+    Base  string `json:"base"`
+}
+
+type gitHubReviewers struct {
+    Reviewers     []string `json:"reviewers,omitempty"`
+    TeamReviewers []string `json:"team_reviewers,omitempty"`
+}
+
+func (g *gitHub) Open(pr PullRequest) (string, error) {
+    var created struct {
+        Number int    `json:"number"`
+        Url    string `json:"html_url"`
+    }
+
+    path := fmt.Sprintf("/repos/%s/pulls", g.repo.Path)
+    body := gitHubPullRequest{Title: pr.Title, Body: pr.Body, Head: pr.Head, Base: pr.Base}
+
+    if err := g.send(http.MethodPost, path, body, &created); err != nil {
+        return "", fmt.Errorf("github: %w", err)
+    }
+
+    if len(pr.Reviewers) == 0 {
+        return created.Url, nil
+    }
+
+    // teams are given as org/team
+    var reviewers gitHubReviewers
+    for _, reviewer := range pr.Reviewers {
+        if _, team, ok := strings.Cut(reviewer, "/"); ok {
+            reviewers.TeamReviewers = append(reviewers.TeamReviewers, team)
+        } else {
+            reviewers.Reviewers = append(reviewers.Reviewers, reviewer)
+        }
+    }
+
+    path = fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", g.repo.Path, created.Number)
+    if err := g.send(http.MethodPost, path, reviewers, nil); err != nil {
+        return created.Url, fmt.Errorf("github: reviewers: %w", err)
+    }
+
+    return created.Url, nil
+}
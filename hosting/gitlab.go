@@ -0,0 +1,75 @@
+package hosting
+
+import (
+    "fmt"
+    "net/http"
+    "net/url"
+)
+
+type gitLab struct {
+    restClient
+    repo Repository
+}
+
+func privateToken(token string) func(request *http.Request) {
+    return func(request *http.Request) {
+        request.Header.Set("PRIVATE-TOKEN", token)
+    }
+}
+
+type gitLabMergeRequest struct {
+    SourceBranch string `json:"source_branch"`
+    TargetBranch string `json:"target_branch"`
+    Title        string `json:"title"`
+    Description  string `json:"description"`
+    ReviewerIds  []int  `json:"reviewer_ids,omitempty"`
+}
+
+// Open requests the reviewers along with the merge request, which needs the ids of their usernames.
+func (g *gitLab) Open(pr PullRequest) (string, error) {
+    reviewerIds, err := g.userIds(pr.Reviewers)
+    if err != nil {
+        return "", fmt.Errorf("gitlab: reviewers: %w", err)
+    }
+
+    var created struct {
+        Url string `json:"web_url"`
+    }
+
+    path := fmt.Sprintf("/projects/%s/merge_requests", url.PathEscape(g.repo.Path))
+    body := gitLabMergeRequest{
+        SourceBranch: pr.Head,
+        TargetBranch: pr.Base,
+        Title:        pr.Title,
+        Description:  pr.Body,
+        ReviewerIds:  reviewerIds,
+    }
+
+    if err := g.send(http.MethodPost, path, body, &created); err != nil {
+        return "", fmt.Errorf("gitlab: %w", err)
+    }
+
+    return created.Url, nil
+}
+
+func (g *gitLab) userIds(usernames []string) ([]int, error) {
+    ids := make([]int, 0, len(usernames))
+
+    for _, username := range usernames {
+        var users []struct {
+            Id int `json:"id"`
+        }
+
+        if err := g.send(http.MethodGet, "/users?username="+url.QueryEscape(username), nil, &users); err != nil {
+            return nil, err
+        }
+
+        if len(users) == 0 {
+            return nil, fmt.Errorf("user %q not found", username)
+        }
+
+        ids = append(ids, users[0].Id)
+    }
+
+    return ids, nil
+}
@@ -0,0 +1,181 @@
+// Package hosting opens pull requests on GitHub, GitLab and Bitbucket Cloud through their REST APIs.
+package hosting
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "twig/log"
+    "twig/network"
+)
+
+const (
+    ProviderGitHub    = "github"
+    ProviderGitLab    = "gitlab"
+    ProviderBitbucket = "bitbucket"
+)
+
+// PullRequest is merged from Head into Base.
+type PullRequest struct {
+    Title     string
+    Body      string
+    Head      string
+    Base      string
+    Reviewers []string // usernames, Bitbucket account ids or {uuid}s
+}
+
+// Provider opens pull requests, GitLab calls them merge requests.
+type Provider interface {
+    // Open opens the pull request and returns its URL. The URL is returned along with
+    // the error if the pull request was opened but the reviewers were not requested.
+    Open(pr PullRequest) (string, error)
+}
+
+type Options struct {
+    Provider string // github, gitlab or bitbucket, detected from the host if empty
+    Api      string // base URL of the REST API, empty for the API of the host
+    Token    string
+}
+
+// New returns the provider hosting the repository.
+func New(repo Repository, options Options, client *http.Client) (Provider, error) {
+    provider := options.Provider
+    if provider == "" {
+        var err error
+        if provider, err = DetectProvider(repo.Host); err != nil {
+            return nil, err
+        }
+    }
+
+    api := strings.TrimSuffix(options.Api, "/")
+
+    switch provider {
+    case ProviderGitHub:
+        if api == "" {
+            api = gitHubApi(repo.Host)
+        }
+        return &gitHub{restClient: newRestClient(client, api, bearer(options.Token)), repo: repo}, nil
+    case ProviderGitLab:
+        if api == "" {
+            api = fmt.Sprintf("https://%s/api/v4", repo.Host)
+        }
+        return &gitLab{restClient: newRestClient(client, api, privateToken(options.Token)), repo: repo}, nil
+    case ProviderBitbucket:
+        if api == "" {
+            api = bitbucketApi
+        }
+        return &bitbucket{restClient: newRestClient(client, api, bitbucketAuth(options.Token)), repo: repo}, nil
+    default:
+        return nil, fmt.Errorf("provider %q is not supported", provider)
+    }
+}
+
+type restClient struct {
+    client *http.Client
+    api    string
+    auth   func(request *http.Request)
+}
+
+func newRestClient(client *http.Client, api string, auth func(request *http.Request)) restClient {
+    return restClient{client: client, api: api, auth: auth}
+}
+
+func bearer(token string) func(request *http.Request) {
+    return func(request *http.Request) {
+        request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+    }
+}
+
+// send sends the body as JSON and decodes the response into result, which may be nil.
+func (c restClient) send(method, path string, body, result any) error {
+    var reader io.Reader
+    if body != nil {
+        encoded, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reader = bytes.NewReader(encoded)
+    }
+
+    log.Debug().Println(fmt.Sprintf("Request %s %q", method, path))
+
+    request, err := http.NewRequest(method, c.api+path, reader)
+    if err != nil {
+        return err
+    }
+
+    request.Header.Set("Accept", "application/json")
+    request.Header.Set("Content-Type", "application/json; charset=UTF-8")
+    c.auth(request)
+
+    response, err := c.client.Do(request)
+    if err != nil {
+        return err
+    }
+
+    defer func(Body io.ReadCloser) {
+        if err := Body.Close(); err != nil {
+            log.Error().Println(fmt.Errorf("request: %w", err))
+        }
+    }(response.Body)
+
+    data, err := io.ReadAll(response.Body)
+    if err != nil {
+        return err
+    }
+
+    log.Debug().Println(fmt.Sprintf("Response %d %q\n%s", response.StatusCode, path, data))
+
+    if response.StatusCode < 200 || response.StatusCode > 299 {
+        return &network.ResponseError{StatusCode: response.StatusCode, Message: errorMessage(data)}
+    }
+
+    if result == nil {
+        return nil
+    }
+
+    return json.Unmarshal(data, result)
+}
+
+// errorMessage reads the messages of GitHub, GitLab and Bitbucket errors, e.g.
+// {"message": "Validation Failed", "errors": [{"message": "A pull request already exists"}]}.
+func errorMessage(data []byte) string {
+    var body struct {
+        Message any `json:"message"`
+        Error   any `json:"error"`
+        Errors  []struct {
+            Message string `json:"message"`
+        } `json:"errors"`
+    }
+
+    if err := json.Unmarshal(data, &body); err != nil {
+        return ""
+    }
+
+    messages := make([]string, 0)
+    for _, value := range []any{body.Message, body.Error} {
+        switch v := value.(type) {
+        case string:
+            messages = append(messages, v)
+        case map[string]any:
+            if message, ok := v["message"].(string); ok {
+                messages = append(messages, message)
+            }
+        case []any:
+            for _, item := range v {
+                messages = append(messages, fmt.Sprint(item))
+            }
+        }
+    }
+
+    for _, e := range body.Errors {
+        if e.Message != "" {
+            messages = append(messages, e.Message)
+        }
+    }
+
+    return strings.Join(messages, ": ")
+}
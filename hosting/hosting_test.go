@@ -0,0 +1,175 @@
+package hosting
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "slices"
+    "testing"
+    "twig/log"
+    "twig/network"
+)
+
+func init() {
+    log.CreateNoOpTestRecorders()
+}
+
+// request is what the stand-in of the hosting API received.
+type request struct {
+    method string
+    path   string
+    auth   string
+    body   map[string]any
+}
+
+// newApiServer answers every request with the response of its method and path, others with 404.
+func newApiServer(t *testing.T, responses map[string]string) (*httptest.Server, *[]request) {
+    received := make([]request, 0)
+
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        route := r.Method + " " + r.URL.RequestURI()
+
+        req := request{method: r.Method, path: r.URL.RequestURI(), auth: r.Header.Get("Authorization")}
+        if token := r.Header.Get("PRIVATE-TOKEN"); token != "" {
+            req.auth = token
+        }
+        _ = json.NewDecoder(r.Body).Decode(&req.body)
+        received = append(received, req)
+
+        response, ok := responses[route]
+        if !ok {
+            w.WriteHeader(http.StatusNotFound)
+            _, _ = w.Write([]byte(`{"message": "Not Found"}`))
+            return
+        }
+
+        w.WriteHeader(http.StatusCreated)
+        _, _ = w.Write([]byte(response))
+    }))
+
+    t.Cleanup(server.Close)
+    return server, &received
+}
+
+func testPullRequest(reviewers ...string) PullRequest {
+    return PullRequest{
+        Title:     "Handle null token",
+        Body:      "ABC-1",
+        Head:      "fix/ABC-1_handle-null-token",
+        Base:      "development",
+        Reviewers: reviewers,
+    }
+}
+
+func TestGitHubOpen(t *testing.T) {
+    server, received := newApiServer(t, map[string]string{
+        "POST /repos/acme/twig/pulls":                       `{"number": 7, "html_url": "https://github.com/acme/twig/pull/7"}`,
+        "POST /repos/acme/twig/pulls/7/requested_reviewers": `{}`,
+    })
+
+    repo := Repository{Host: "github.com", Path: "acme/twig"}
+    provider, err := New(repo, Options{Api: server.URL, Token: "secret"}, server.Client())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    subject, err := provider.Open(testPullRequest("jane", "acme/backend"))
+    if subject != "https://github.com/acme/twig/pull/7" || err != nil {
+        t.Fatalf(`Open() = %q %v, want the URL of the pull request`, subject, err)
+    }
+
+    pr, reviewers := (*received)[0], (*received)[1]
+    if pr.auth != "Bearer secret" || pr.body["head"] != "fix/ABC-1_handle-null-token" || pr.body["base"] != "development" || pr.body["title"] != "Handle null token" {
+        t.Errorf(`Open() sent %+v, want the pull request`, pr)
+    }
+
+    if reviewers.body["reviewers"].([]any)[0] != "jane" || reviewers.body["team_reviewers"].([]any)[0] != "backend" {
+        t.Errorf(`Open() sent %+v, want jane and the backend team`, reviewers)
+    }
+}
+
+func TestGitHubOpenReviewersFailed(t *testing.T) {
+    server, _ := newApiServer(t, map[string]string{
+        "POST /repos/acme/twig/pulls": `{"number": 7, "html_url": "https://github.com/acme/twig/pull/7"}`,
+    })
+
+    provider, _ := New(Repository{Host: "github.com", Path: "acme/twig"}, Options{Api: server.URL}, server.Client())
+
+    if subject, err := provider.Open(testPullRequest("jane")); subject == "" || err == nil {
+        t.Errorf(`Open() = %q %v, want the URL along with an error`, subject, err)
+    }
+}
+
+func TestGitLabOpen(t *testing.T) {
+    server, received := newApiServer(t, map[string]string{
+        "GET /users?username=jane":                           `[{"id": 42}]`,
+        "POST /projects/acme%2Fbackend%2Fapi/merge_requests": `{"web_url": "https://gitlab.com/acme/backend/api/-/merge_requests/3"}`,
+    })
+
+    repo := Repository{Host: "gitlab.com", Path: "acme/backend/api"}
+    provider, err := New(repo, Options{Api: server.URL, Token: "secret"}, server.Client())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    subject, err := provider.Open(testPullRequest("jane"))
+    if subject != "https://gitlab.com/acme/backend/api/-/merge_requests/3" || err != nil {
+        t.Fatalf(`Open() = %q %v, want the URL of the merge request`, subject, err)
+    }
+
+    mr := (*received)[1]
+    if mr.auth != "secret" || mr.body["source_branch"] != "fix/ABC-1_handle-null-token" || mr.body["target_branch"] != "development" {
+        t.Errorf(`Open() sent %+v, want the merge request`, mr)
+    }
+
+    if ids := mr.body["reviewer_ids"].([]any); !slices.Equal(ids, []any{float64(42)}) {
+        t.Errorf(`Open() sent reviewer_ids %v, want the id of jane`, ids)
+    }
+}
+
+func TestBitbucketOpen(t *testing.T) {
+    server, received := newApiServer(t, map[string]string{
+        "POST /repositories/acme/twig/pullrequests": `{"links": {"html": {"href": "https://bitbucket.org/acme/twig/pull-requests/5"}}}`,
+    })
+
+    repo := Repository{Host: "bitbucket.org", Path: "acme/twig"}
+    provider, err := New(repo, Options{Api: server.URL, Token: "jane:app-password"}, server.Client())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    subject, err := provider.Open(testPullRequest("{1234}", "557058:abcd"))
+    if subject != "https://bitbucket.org/acme/twig/pull-requests/5" || err != nil {
+        t.Fatalf(`Open() = %q %v, want the URL of the pull request`, subject, err)
+    }
+
+    pr := (*received)[0]
+    reviewers := pr.body["reviewers"].([]any)
+    if pr.auth == "" || reviewers[0].(map[string]any)["uuid"] != "{1234}" || reviewers[1].(map[string]any)["account_id"] != "557058:abcd" {
+        t.Errorf(`Open() sent %+v, want basic auth and the reviewers`, pr)
+    }
+}
+
+func TestOpenError(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusUnprocessableEntity)
+        _, _ = w.Write([]byte(`{"message": "Validation Failed", "errors": [{"message": "A pull request already exists"}]}`))
+    }))
+    t.Cleanup(server.Close)
+
+    provider, _ := New(Repository{Host: "github.com", Path: "acme/twig"}, Options{Api: server.URL}, server.Client())
+
+    _, err := provider.Open(testPullRequest())
+
+    var responseErr *network.ResponseError
+    if !errors.As(err, &responseErr) || responseErr.Message != "Validation Failed: A pull request already exists" {
+        t.Errorf(`Open() = %v, want the messages of the response`, err)
+    }
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+    if _, err := New(Repository{Host: "git.example.com", Path: "acme/twig"}, Options{}, http.DefaultClient); err == nil {
+        t.Error(`New() returned no error, want error for an unknown host`)
+    }
+}
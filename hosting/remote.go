@@ -0,0 +1,51 @@
+package hosting
+
+import (
+    "fmt"
+    "net/url"
+    "regexp"
+    "strings"
+)
+
+// Repository is the repository a remote points to.
+type Repository struct {
+    Host string // e.g. github.com
+    Path string // owner and name along with GitLab subgroups, e.g. acme/backend/api
+}
+
+// scp-like syntax of ssh remotes, e.g. git@github.com:acme/twig.git
+var scpLike = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ParseRemote reads the host and path of https, ssh and scp-like remote URLs.
+func ParseRemote(remoteUrl string) (Repository, error) {
+    var host, path string
+
+    if strings.Contains(remoteUrl, "://") {
+        u, err := url.Parse(remoteUrl)
+        if err != nil {
+            return Repository{}, fmt.Errorf("remote %q: %w", remoteUrl, err)
+        }
+
+        host, path = u.Hostname(), u.Path
+    } else if match := scpLike.FindStringSubmatch(remoteUrl); match != nil {
+        host, path = match[1], match[2]
+    }
+
+    path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+    if host == "" || !strings.Contains(path, "/") {
+        return Repository{}, fmt.Errorf("remote %q does not point to a repository", remoteUrl)
+    }
+
+    return Repository{Host: strings.ToLower(host), Path: path}, nil
+}
+
+// DetectProvider tells the provider by the host, self-hosted instances with other names need pr.provider.
+func DetectProvider(host string) (string, error) {
+    for _, provider := range []string{ProviderGitHub, ProviderGitLab, ProviderBitbucket} {
+        if strings.Contains(host, provider) {
+            return provider, nil
+        }
+    }
+
+    return "", fmt.Errorf("unable to tell the provider of %q", host)
+}
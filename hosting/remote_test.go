@@ -0,0 +1,47 @@
+package hosting
+
+import (
+    "testing"
+)
+
+func TestParseRemote(t *testing.T) {
+    tests := map[string]Repository{
+        "https://github.com/acme/twig.git":                       {Host: "github.com", Path: "acme/twig"},
+        "https://user@bitbucket.org/acme/twig.git":               {Host: "bitbucket.org", Path: "acme/twig"},
+        "git@github.com:acme/twig.git":                           {Host: "github.com", Path: "acme/twig"},
+        "git@gitlab.com:acme/backend/api":                        {Host: "gitlab.com", Path: "acme/backend/api"},
+        "ssh://git@GitLab.example.com:2222/acme/backend/api.git": {Host: "gitlab.example.com", Path: "acme/backend/api"},
+    }
+
+    for remoteUrl, want := range tests {
+        if subject, err := ParseRemote(remoteUrl); subject != want || err != nil {
+            t.Errorf(`ParseRemote(%q) = %+v %v, want match for %+v`, remoteUrl, subject, err, want)
+        }
+    }
+}
+
+func TestParseRemoteInvalid(t *testing.T) {
+    for _, remoteUrl := range []string{"", "/srv/git/twig.git", "https://github.com/twig"} {
+        if subject, err := ParseRemote(remoteUrl); err == nil {
+            t.Errorf(`ParseRemote(%q) = %+v, want error`, remoteUrl, subject)
+        }
+    }
+}
+
+func TestDetectProvider(t *testing.T) {
+    tests := map[string]string{
+        "github.com":         ProviderGitHub,
+        "gitlab.example.com": ProviderGitLab,
+        "bitbucket.org":      ProviderBitbucket,
+    }
+
+    for host, want := range tests {
+        if subject, err := DetectProvider(host); subject != want || err != nil {
+            t.Errorf(`DetectProvider(%q) = %q %v, want match for %q`, host, subject, err, want)
+        }
+    }
+
+    if subject, err := DetectProvider("git.example.com"); err == nil {
+        t.Errorf(`DetectProvider("git.example.com") = %q, want error`, subject)
+    }
+}
@@ -0,0 +1,70 @@
+package issue
+
+import (
+    "strings"
+    "twig/network"
+    "unicode"
+)
+
+type mappingRule struct {
+    words   []string
+    mapping string
+}
+
+// mappingRules pair words of Jira issue type names with mapping types, the first match wins.
+// A word matches the beginning of a word of the name, e.g. "doc" matches "Documentation".
+var mappingRules = []mappingRule{
+    {words: []string{"bug", "defect", "incident", "hotfix"}, mapping: "fix"},
+    {words: []string{"story", "feature", "requirement"}, mapping: "feat"},
+    {words: []string{"improvement", "refactor", "debt"}, mapping: "refactor"},
+    {words: []string{"doc"}, mapping: "docs"},
+    {words: []string{"test", "qa"}, mapping: "test"},
+    {words: []string{"performance", "perf"}, mapping: "perf"},
+    {words: []string{"revert", "rollback"}, mapping: "revert"},
+    {words: []string{"build", "release"}, mapping: "build"},
+    {words: []string{"ci", "pipeline"}, mapping: "ci"},
+    {words: []string{"style", "design"}, mapping: "style"},
+    {words: []string{"task", "chore", "spike", "maintenance"}, mapping: "chore"},
+}
+
+// ProposeType returns the mapping type for a Jira issue type name, e.g. "fix" for "Bug",
+// or nothing when no rule matches.
+func ProposeType(name string) string {
+    words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+        return !unicode.IsLetter(r)
+    })
+
+    for _, rule := range mappingRules {
+        for _, word := range words {
+            for _, prefix := range rule.words {
+                if strings.HasPrefix(word, prefix) {
+                    return rule.mapping
+                }
+            }
+        }
+    }
+
+    return ""
+}
+
+// ProposeMapping assigns a mapping type to every issue type id. Ids already in the current mapping
+// keep their type, the others get the proposed one or nothing when no rule matches.
+func ProposeMapping(types []network.IssueType, current map[string][]string) map[string]string {
+    mapped := make(map[string]string)
+    for name, ids := range current {
+        for _, id := range ids {
+            mapped[id] = name
+        }
+    }
+
+    assigned := make(map[string]string)
+    for _, issueType := range types {
+        if name, ok := mapped[issueType.Id]; ok {
+            assigned[issueType.Id] = name
+        } else {
+            assigned[issueType.Id] = ProposeType(issueType.Name)
+        }
+    }
+
+    return assigned
+}
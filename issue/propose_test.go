@@ -0,0 +1,48 @@
+package issue
+
+import (
+    "maps"
+    "testing"
+    "twig/log"
+    "twig/network"
+)
+
+func init() {
+    log.CreateNoOpTestRecorders()
+}
+
+func TestProposeType(t *testing.T) {
+    cases := map[string]string{
+        "Bug":            "fix",
+        "Story":          "feat",
+        "New Feature":    "feat",
+        "Task":           "chore",
+        "Sub-task":       "chore",
+        "Documentation":  "docs",
+        "Technical Debt": "refactor",
+        "Test Case":      "test",
+        "Epic":           "",
+    }
+
+    for in, want := range cases {
+        if subject := ProposeType(in); subject != want {
+            t.Errorf(`ProposeType(%q) = %q, want match for %q`, in, subject, want)
+        }
+    }
+}
+
+func TestProposeMappingKeepsCurrent(t *testing.T) {
+    in := []network.IssueType{
+        {Id: "10001", Name: "Bug"},
+        {Id: "10002", Name: "Story"},
+        {Id: "10003", Name: "Incident"},
+        {Id: "10004", Name: "Epic"},
+    }
+
+    subject := ProposeMapping(in, map[string][]string{"hotfix": {"10003"}, "feat": {"0"}})
+
+    want := map[string]string{"10001": "fix", "10002": "feat", "10003": "hotfix", "10004": ""}
+    if !maps.Equal(subject, want) {
+        t.Errorf(`ProposeMapping(in) = %v, want match for %v`, subject, want)
+    }
+}
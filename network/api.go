@@ -5,14 +5,21 @@ import (
     "encoding/json"
     "fmt"
     "net/http"
+    "net/url"
+    "strings"
     "twig/log"
 )
 
 type JiraApi interface {
+    GetCurrentUser() (*JiraUser, error)
     GetJiraIssueTypes() ([]IssueType, error)
+    GetJiraProjectIssueTypes(projectKey string) ([]IssueType, error)
     GetJiraIssue(issueKey string) (*JiraIssue, error)
     GetJiraIssueStatus(issueKey string, hasAssignee bool) (*JiraIssue, error)
     GetJiraIssueStatusBulk(issueKeys []string, hasAssignee bool) ([]JiraIssue, error)
+    GetJiraIssueDetails(issueKey, sprintField string) (*JiraIssue, error)
+    GetJiraIssueDetailsBulk(issueKeys []string, sprintField string) ([]JiraIssue, error)
+    GetJiraIssueDescription(issueKey string) (*JiraIssue, error)
 }
 
 type mixedJiraApi struct {
@@ -25,6 +32,24 @@ func NewJiraApi(client Client) JiraApi {
     }
 }
 
+func (api *mixedJiraApi) GetCurrentUser() (*JiraUser, error) {
+    log.Debug().Println(fmt.Sprintf("Request %s 'myself'", http.MethodGet))
+
+    response, err := api.client.SendRequest(http.MethodGet, "myself", nil)
+    if err != nil {
+        return nil, err
+    }
+
+    log.Debug().Println(fmt.Sprintf("Response %d 'myself'\n%s", response.statusCode, response.body))
+
+    var jiraUser JiraUser
+    if err := json.Unmarshal(response.body, &jiraUser); err != nil {
+        return nil, err
+    }
+
+    return &jiraUser, nil
+}
+
 func (api *mixedJiraApi) GetJiraIssueTypes() ([]IssueType, error) {
     log.Debug().Println(fmt.Sprintf("Request %s 'issuetype'", http.MethodGet))
     path := "issuetype"
@@ -45,6 +70,25 @@ func (api *mixedJiraApi) GetJiraIssueTypes() ([]IssueType, error) {
     return jiraIssue, nil
 }
 
+func (api *mixedJiraApi) GetJiraProjectIssueTypes(projectKey string) ([]IssueType, error) {
+    log.Debug().Println(fmt.Sprintf("Request %s 'project'", http.MethodGet))
+    path := fmt.Sprintf("project/%s", url.PathEscape(projectKey))
+
+    response, err := api.client.SendRequest(http.MethodGet, path, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    log.Debug().Println(fmt.Sprintf("Response %d 'project'\n%s", response.statusCode, response.body))
+
+    var jiraProject JiraProject
+    if err := json.Unmarshal(response.body, &jiraProject); err != nil {
+        return nil, err
+    }
+
+    return jiraProject.IssueTypes, nil
+}
+
 func (api *mixedJiraApi) GetJiraIssue(issueKey string) (*JiraIssue, error) {
     log.Debug().Println(fmt.Sprintf("Request %s 'issue'", http.MethodGet))
     path := fmt.Sprintf("issue/%s?fields=issuetype,summary", issueKey)
@@ -117,3 +161,90 @@ func (api *mixedJiraApi) GetJiraIssueStatusBulk(issueKeys []string, hasAssignee
 
     return jiraIssues.Issues, nil
 }
+
+// detailFields are shown by twig list, the sprint is a custom field which differs between Jira instances.
+func detailFields(sprintField string) []string {
+    fields := []string{"issuetype", "summary", "status", "assignee"}
+    if sprintField != "" {
+        fields = append(fields, sprintField)
+    }
+
+    return fields
+}
+
+func (api *mixedJiraApi) GetJiraIssueDetails(issueKey, sprintField string) (*JiraIssue, error) {
+    log.Debug().Println(fmt.Sprintf("Request %s 'issue details'", http.MethodGet))
+    path := fmt.Sprintf("issue/%s?fields=%s", issueKey, strings.Join(detailFields(sprintField), ","))
+
+    response, err := api.client.SendRequest(http.MethodGet, path, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    log.Debug().Println(fmt.Sprintf("Response %d 'issue details'\n%s", response.statusCode, response.body))
+
+    var jiraIssue JiraIssue
+    if err := json.Unmarshal(response.body, &jiraIssue); err != nil {
+        return nil, err
+    }
+
+    if sprintField != "" {
+        var raw rawIssue
+        if err := json.Unmarshal(response.body, &raw); err != nil {
+            return nil, err
+        }
+
+        if jiraIssue.Fields.Sprints, err = parseSprints(raw.Fields[sprintField]); err != nil {
+            return nil, err
+        }
+    }
+
+    return &jiraIssue, nil
+}
+
+func (api *mixedJiraApi) GetJiraIssueDetailsBulk(issueKeys []string, sprintField string) ([]JiraIssue, error) {
+    log.Debug().Println(fmt.Sprintf("Request %s 'issue details bulk'", http.MethodPost))
+
+    body := JiraIssueBulkRequest{
+        Fields:    detailFields(sprintField),
+        IssueKeys: issueKeys,
+    }
+
+    encodedBody, _ := json.Marshal(body)
+    response, err := api.client.SendRequest(http.MethodPost, "issue/bulkfetch", bytes.NewBuffer(encodedBody))
+    if err != nil {
+        return nil, err
+    }
+
+    log.Debug().Println(fmt.Sprintf("Response %d 'issue details bulk'\n%s", response.statusCode, response.body))
+
+    var jiraIssues JiraIssues
+    if err := json.Unmarshal(response.body, &jiraIssues); err != nil {
+        return nil, err
+    }
+
+    if err := decodeSprints(response.body, sprintField, jiraIssues.Issues); err != nil {
+        return nil, err
+    }
+
+    return jiraIssues.Issues, nil
+}
+
+func (api *mixedJiraApi) GetJiraIssueDescription(issueKey string) (*JiraIssue, error) {
+    log.Debug().Println(fmt.Sprintf("Request %s 'issue description'", http.MethodGet))
+    path := fmt.Sprintf("issue/%s?fields=issuetype,summary,description", issueKey)
+
+    response, err := api.client.SendRequest(http.MethodGet, path, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    log.Debug().Println(fmt.Sprintf("Response %d 'issue description'\n%s", response.statusCode, response.body))
+
+    var jiraIssue JiraIssue
+    if err := json.Unmarshal(response.body, &jiraIssue); err != nil {
+        return nil, err
+    }
+
+    return &jiraIssue, nil
+}
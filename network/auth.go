@@ -0,0 +1,54 @@
+package network
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+    "twig/config"
+    "twig/log"
+)
+
+// DetectAuth sends GET /myself with the configured credentials, trying the configured auth type first
+// and then the others. It returns the first auth type Jira accepts along with the authenticated user.
+func DetectAuth(client *http.Client) (string, *JiraUser, error) {
+    credentials := jiraCredentials{
+        host:  config.GetString(config.ProjectHost),
+        auth:  config.GetString(config.ProjectAuth),
+        email: config.GetString(config.ProjectEmail),
+        token: config.GetString(config.ProjectToken),
+    }
+
+    return detectAuth(client, credentials)
+}
+
+func detectAuth(client *http.Client, credentials jiraCredentials) (string, *JiraUser, error) {
+    candidates := []string{BasicType, BearerType}
+    if auth := strings.ToLower(credentials.auth); auth == BearerType {
+        candidates = []string{BearerType, BasicType}
+    }
+
+    errs := make([]error, 0, len(candidates))
+
+    for _, auth := range candidates {
+        attempt := credentials
+        attempt.auth = auth
+
+        api := NewJiraApi(&httpClient{credentials: &attempt, client: client})
+        user, err := api.GetCurrentUser()
+        if err == nil {
+            log.Debug().Println(fmt.Sprintf("Jira accepts %s auth", auth))
+            return auth, user, nil
+        }
+
+        errs = append(errs, fmt.Errorf("%s auth: %w", auth, err))
+
+        // only a rejected auth type is worth another try, other errors repeat for every type
+        var responseErr *ResponseError
+        if !errors.As(err, &responseErr) {
+            break
+        }
+    }
+
+    return "", nil, errors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package network
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func newMyselfServer(t *testing.T, accepted string) *httptest.Server {
+    server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path != "/rest/api/2/myself" {
+            w.WriteHeader(http.StatusNotFound)
+            return
+        }
+
+        _, password, isBasic := r.BasicAuth()
+        isBearer := r.Header.Get("Authorization") == "Bearer good"
+
+        if (accepted == BasicType && !(isBasic && password == "good")) || (accepted == BearerType && !isBearer) {
+            w.WriteHeader(http.StatusUnauthorized)
+            _ = json.NewEncoder(w).Encode(JiraError{ErrorMessages: []string{"unauthorized"}})
+            return
+        }
+
+        _ = json.NewEncoder(w).Encode(JiraUser{DisplayName: "Example User", Email: "example.user@example.com"})
+    }))
+
+    t.Cleanup(server.Close)
+    return server
+}
+
+func testCredentials(server *httptest.Server, auth string) jiraCredentials {
+    return jiraCredentials{
+        host:  strings.TrimPrefix(server.URL, "https://"),
+        auth:  auth,
+        email: "example.user@example.com",
+        token: "good",
+    }
+}
+
+func TestDetectAuthBearer(t *testing.T) {
+    server := newMyselfServer(t, BearerType)
+
+    auth, user, err := detectAuth(server.Client(), testCredentials(server, BasicType))
+    if err != nil {
+        t.Fatalf(`detectAuth() returned error %v`, err)
+    }
+
+    if auth != BearerType || user.DisplayName != "Example User" {
+        t.Errorf(`detectAuth() = %q, %q, want match for %q, "Example User"`, auth, user.DisplayName, BearerType)
+    }
+}
+
+func TestDetectAuthBasic(t *testing.T) {
+    server := newMyselfServer(t, BasicType)
+
+    auth, _, err := detectAuth(server.Client(), testCredentials(server, ""))
+    if err != nil || auth != BasicType {
+        t.Errorf(`detectAuth() = %q, %v, want match for %q`, auth, err, BasicType)
+    }
+}
+
+func TestDetectAuthRejected(t *testing.T) {
+    server := newMyselfServer(t, BasicType)
+
+    credentials := testCredentials(server, BasicType)
+    credentials.token = "bad"
+
+    if auth, _, err := detectAuth(server.Client(), credentials); err == nil {
+        t.Errorf(`detectAuth() = %q, nil, want error`, auth)
+    }
+}
@@ -0,0 +1,188 @@
+package network
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+    "twig/log"
+)
+
+// BatchFunc requests a single batch of issues from Jira.
+type BatchFunc func(issueKeys []string) ([]JiraIssue, error)
+
+// Limiter blocks the caller until the next request is allowed.
+type Limiter interface {
+    Wait()
+}
+
+// BatchError reports the keys of a batch that could not be fetched.
+type BatchError struct {
+    Keys []string
+    Err  error
+}
+
+func (e *BatchError) Error() string {
+    return fmt.Sprintf("batch [%s]: %s", strings.Join(e.Keys, ","), e.Err.Error())
+}
+
+func (e *BatchError) Unwrap() error {
+    return e.Err
+}
+
+// BulkFetcher splits issue keys into batches and requests them with bounded concurrency.
+type BulkFetcher struct {
+    limiter   Limiter
+    workers   int
+    batchSize int
+}
+
+func NewBulkFetcher(limiter Limiter, workers, batchSize int) *BulkFetcher {
+    if workers < 1 {
+        workers = 1
+    }
+
+    if batchSize < 1 {
+        batchSize = 1
+    }
+
+    return &BulkFetcher{
+        limiter:   limiter,
+        workers:   workers,
+        batchSize: batchSize,
+    }
+}
+
+// Fetch requests every key exactly once. Issues of successful batches are returned
+// even when other batches fail; failures are joined from BatchError values.
+func (f *BulkFetcher) Fetch(issueKeys []string, fetch BatchFunc) ([]JiraIssue, error) {
+    batches := f.split(issueKeys)
+    log.Debug().Println(fmt.Sprintf("Bulk fetch %d keys in %d batches", len(issueKeys), len(batches)))
+
+    jobs := make(chan []string)
+    results := make(chan batchResult, len(batches))
+
+    var wg sync.WaitGroup
+    for i := 0; i < min(f.workers, len(batches)); i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+
+            for batch := range jobs {
+                if f.limiter != nil {
+                    f.limiter.Wait()
+                }
+
+                issues, err := fetch(batch)
+                results <- batchResult{keys: batch, issues: issues, err: err}
+            }
+        }()
+    }
+
+    for _, batch := range batches {
+        jobs <- batch
+    }
+    close(jobs)
+
+    wg.Wait()
+    close(results)
+
+    jiraIssues := make([]JiraIssue, 0, len(issueKeys))
+    errs := make([]error, 0)
+
+    for result := range results {
+        if result.err != nil {
+            log.Debug().Println(fmt.Sprintf("Bulk issue: %s", result.err.Error()))
+            errs = append(errs, &BatchError{Keys: result.keys, Err: result.err})
+            continue
+        }
+
+        jiraIssues = append(jiraIssues, result.issues...)
+    }
+
+    return jiraIssues, errors.Join(errs...)
+}
+
+// FetchStatuses fetches the status (and optionally the assignee) of every key.
+func (f *BulkFetcher) FetchStatuses(api JiraApi, issueKeys []string, hasAssignee bool) ([]JiraIssue, error) {
+    return f.Fetch(issueKeys, func(batch []string) ([]JiraIssue, error) {
+        return api.GetJiraIssueStatusBulk(batch, hasAssignee)
+    })
+}
+
+// FetchDetails fetches the summary, status, assignee and sprint of every key.
+func (f *BulkFetcher) FetchDetails(api JiraApi, issueKeys []string, sprintField string) ([]JiraIssue, error) {
+    return f.Fetch(issueKeys, func(batch []string) ([]JiraIssue, error) {
+        return api.GetJiraIssueDetailsBulk(batch, sprintField)
+    })
+}
+
+func (f *BulkFetcher) split(issueKeys []string) [][]string {
+    batches := make([][]string, 0, (len(issueKeys)+f.batchSize-1)/f.batchSize)
+
+    for start := 0; start < len(issueKeys); start += f.batchSize {
+        end := min(start+f.batchSize, len(issueKeys))
+        batches = append(batches, issueKeys[start:end])
+    }
+
+    return batches
+}
+
+type batchResult struct {
+    keys   []string
+    issues []JiraIssue
+    err    error
+}
+
+// TokenBucket is a Limiter which allows bursts up to its capacity and refills at a fixed rate.
+type TokenBucket struct {
+    mu       sync.Mutex
+    tokens   float64
+    capacity float64
+    perSec   float64
+    last     time.Time
+
+    now   func() time.Time
+    sleep func(time.Duration)
+}
+
+func NewTokenBucket(perSecond, burst int) *TokenBucket {
+    if perSecond < 1 {
+        perSecond = 1
+    }
+
+    if burst < 1 {
+        burst = 1
+    }
+
+    return &TokenBucket{
+        tokens:   float64(burst),
+        capacity: float64(burst),
+        perSec:   float64(perSecond),
+        last:     time.Now(),
+        now:      time.Now,
+        sleep:    time.Sleep,
+    }
+}
+
+func (b *TokenBucket) Wait() {
+    for {
+        b.mu.Lock()
+
+        now := b.now()
+        b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.perSec)
+        b.last = now
+
+        if b.tokens >= 1 {
+            b.tokens--
+            b.mu.Unlock()
+            return
+        }
+
+        wait := time.Duration((1 - b.tokens) / b.perSec * float64(time.Second))
+        b.mu.Unlock()
+
+        b.sleep(wait)
+    }
+}
@@ -0,0 +1,178 @@
+package network
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "sync"
+    "testing"
+    "time"
+    "twig/log"
+)
+
+func init() {
+    log.CreateNoOpTestRecorders()
+}
+
+type fakeJiraApi struct {
+    mu       sync.Mutex
+    requests [][]string
+    failKey  string
+}
+
+func (api *fakeJiraApi) GetCurrentUser() (*JiraUser, error) {
+    return nil, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssueTypes() ([]IssueType, error) {
+    return nil, nil
+}
+
+func (api *fakeJiraApi) GetJiraProjectIssueTypes(projectKey string) ([]IssueType, error) {
+    return nil, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssue(issueKey string) (*JiraIssue, error) {
+    return nil, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssueStatus(issueKey string, hasAssignee bool) (*JiraIssue, error) {
+    return nil, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssueStatusBulk(issueKeys []string, hasAssignee bool) ([]JiraIssue, error) {
+    api.mu.Lock()
+    api.requests = append(api.requests, slices.Clone(issueKeys))
+    api.mu.Unlock()
+
+    if api.failKey != "" && slices.Contains(issueKeys, api.failKey) {
+        return nil, errors.New("unauthorized")
+    }
+
+    issues := make([]JiraIssue, len(issueKeys))
+    for i, key := range issueKeys {
+        issues[i] = JiraIssue{Key: key}
+    }
+
+    return issues, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssueDetails(issueKey, sprintField string) (*JiraIssue, error) {
+    return nil, nil
+}
+
+func (api *fakeJiraApi) GetJiraIssueDetailsBulk(issueKeys []string, sprintField string) ([]JiraIssue, error) {
+    return api.GetJiraIssueStatusBulk(issueKeys, false)
+}
+
+func (api *fakeJiraApi) GetJiraIssueDescription(issueKey string) (*JiraIssue, error) {
+    return nil, nil
+}
+
+type noOpLimiter struct{}
+
+func (l noOpLimiter) Wait() {}
+
+func generateKeys(size int) []string {
+    keys := make([]string, size)
+    for i := range keys {
+        keys[i] = fmt.Sprintf("TST-%d", i+1)
+    }
+
+    return keys
+}
+
+func issueKeys(issues []JiraIssue) []string {
+    keys := make([]string, len(issues))
+    for i, issue := range issues {
+        keys[i] = issue.Key
+    }
+
+    slices.Sort(keys)
+    return keys
+}
+
+func TestFetchStatusesEveryKeyFetched(t *testing.T) {
+    for _, size := range []int{1, 99, 100, 101, 250} {
+        api := &fakeJiraApi{}
+        keys := generateKeys(size)
+
+        issues, err := NewBulkFetcher(noOpLimiter{}, 5, 100).FetchStatuses(api, keys, true)
+        if err != nil {
+            t.Fatalf(`FetchStatuses(%d keys) returned error %v`, size, err)
+        }
+
+        want := slices.Clone(keys)
+        slices.Sort(want)
+
+        if subject := issueKeys(issues); !slices.Equal(subject, want) {
+            t.Errorf(`FetchStatuses(%d keys) = %d issues, want %d`, size, len(subject), len(want))
+        }
+    }
+}
+
+func TestFetchStatusesBatchSize(t *testing.T) {
+    api := &fakeJiraApi{}
+
+    _, _ = NewBulkFetcher(noOpLimiter{}, 2, 100).FetchStatuses(api, generateKeys(250), false)
+
+    if len(api.requests) != 3 {
+        t.Fatalf(`FetchStatuses(250 keys) sent %d requests, want 3`, len(api.requests))
+    }
+
+    for _, request := range api.requests {
+        if len(request) > 100 {
+            t.Errorf(`FetchStatuses(250 keys) sent batch of %d keys, want at most 100`, len(request))
+        }
+    }
+}
+
+func TestFetchStatusesPartialResults(t *testing.T) {
+    api := &fakeJiraApi{failKey: "TST-150"}
+
+    issues, err := NewBulkFetcher(noOpLimiter{}, 3, 100).FetchStatuses(api, generateKeys(250), false)
+
+    if len(issues) != 150 {
+        t.Errorf(`FetchStatuses() = %d issues, want 150`, len(issues))
+    }
+
+    var batchErr *BatchError
+    if !errors.As(err, &batchErr) {
+        t.Fatalf(`FetchStatuses() error = %v, want BatchError`, err)
+    }
+
+    if len(batchErr.Keys) != 100 || !slices.Contains(batchErr.Keys, "TST-150") {
+        t.Errorf(`BatchError.Keys = %d keys, want failed batch with "TST-150"`, len(batchErr.Keys))
+    }
+}
+
+func TestFetchEmptyKeys(t *testing.T) {
+    api := &fakeJiraApi{}
+
+    issues, err := NewBulkFetcher(noOpLimiter{}, 5, 100).FetchStatuses(api, nil, false)
+
+    if err != nil || len(issues) != 0 || len(api.requests) != 0 {
+        t.Errorf(`FetchStatuses(nil) = %d issues, %d requests, %v; want none`, len(issues), len(api.requests), err)
+    }
+}
+
+func TestTokenBucketWaitsWhenEmpty(t *testing.T) {
+    now := time.Unix(0, 0)
+    var slept time.Duration
+
+    bucket := NewTokenBucket(5, 1)
+    bucket.last = now
+    bucket.now = func() time.Time { return now }
+    bucket.sleep = func(d time.Duration) {
+        slept += d
+        now = now.Add(d)
+    }
+
+    bucket.Wait()
+    bucket.Wait()
+
+    want := time.Second / 5
+    if slept != want {
+        t.Errorf(`TokenBucket.Wait() slept %v, want %v`, slept, want)
+    }
+}
@@ -2,13 +2,14 @@ package network
 
 import (
     "encoding/json"
-    "errors"
     "fmt"
     "io"
     "net/http"
     "strings"
+    "sync"
     "twig/config"
     "twig/log"
+    "twig/secret"
 )
 
 type Client interface {
@@ -19,6 +20,10 @@ type Client interface {
 type httpClient struct {
     credentials *jiraCredentials
     client      *http.Client
+
+    tokenOnce sync.Once
+    token     string
+    tokenErr  error
 }
 
 func NewHttpClient(client *http.Client) Client {
@@ -81,13 +86,14 @@ func (c *httpClient) SendRequest(method, path string, body io.Reader) (*Response
         }, nil
     }
 
+    // Jira answers with HTML instead of JSON to some auth errors
     var jiraError JiraError
     if err := json.Unmarshal(data, &jiraError); err != nil {
-        return nil, err
+        return nil, &ResponseError{StatusCode: response.StatusCode}
     }
 
     errs := strings.Join(jiraError.ErrorMessages[:], "\n")
-    return nil, errors.New(errs)
+    return nil, &ResponseError{StatusCode: response.StatusCode, Message: errs}
 }
 
 func (c *httpClient) cofigureHeaders(method string, request *http.Request) error {
@@ -106,16 +112,38 @@ func (c *httpClient) cofigureHeaders(method string, request *http.Request) error
 func (c *httpClient) addAuthHeader(request *http.Request, credentials *jiraCredentials) error {
     auth := strings.ToLower(credentials.auth)
 
+    token, err := c.resolveToken()
+    if err != nil {
+        return err
+    }
+
     switch auth {
     case BasicType:
         log.Debug().Println("Use Basic Auth")
-        request.SetBasicAuth(credentials.email, credentials.token)
+        request.SetBasicAuth(credentials.email, token)
         return nil
     case BearerType:
         log.Debug().Println("Use Bearer Auth")
-        request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", credentials.token))
+        request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
         return nil
     default:
         return fmt.Errorf("%q does not support %q auth", config.FromToken(config.ProjectAuth), auth)
     }
 }
+
+// resolveToken reads the token from its secret backend once, requests may be sent concurrently.
+func (c *httpClient) resolveToken() (string, error) {
+    c.tokenOnce.Do(func() {
+        account := secret.Account{
+            Host: c.credentials.host,
+            User: c.credentials.email,
+        }
+
+        c.token, c.tokenErr = secret.Resolve(c.credentials.token, account)
+        if c.tokenErr != nil {
+            c.tokenErr = fmt.Errorf("%q %w", config.FromToken(config.ProjectToken), c.tokenErr)
+        }
+    })
+
+    return c.token, c.tokenErr
+}
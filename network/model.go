@@ -1,5 +1,10 @@
 package network
 
+import (
+    "fmt"
+    "net/http"
+)
+
 const (
     BasicType  = "basic"
     BearerType = "bearer"
@@ -23,18 +28,28 @@ type JiraIssue struct {
 }
 
 type IssueFields struct {
-    Type     *IssueType     `json:"issuetype,omitempty"`
-    Summary  *string        `json:"summary,omitempty"`
-    Status   *IssueStatus   `json:"status,omitempty"`
-    Assignee *IssueAssignee `json:"assignee,omitempty"`
+    Type        *IssueType     `json:"issuetype,omitempty"`
+    Summary     *string        `json:"summary,omitempty"`
+    Description *string        `json:"description,omitempty"` // wiki markup of API version 2
+    Status      *IssueStatus   `json:"status,omitempty"`
+    Assignee    *IssueAssignee `json:"assignee,omitempty"`
+    Sprints     []IssueSprint  `json:"-"` // read from the custom field given to GetJiraIssueDetails
 }
 
 type IssueType struct {
-    Id   string `json:"id"`
-    Name string `json:"name"`
+    Id      string `json:"id"`
+    Name    string `json:"name"`
+    Subtask bool   `json:"subtask,omitempty"`
+}
+
+type JiraProject struct {
+    Key        string      `json:"key"`
+    Name       string      `json:"name"`
+    IssueTypes []IssueType `json:"issueTypes"`
 }
 
 type IssueStatus struct {
+    Name     string              `json:"name"`
     Category IssueStatusCategory `json:"statusCategory"`
 }
 
@@ -44,13 +59,40 @@ type IssueStatusCategory struct {
 }
 
 type IssueAssignee struct {
-    Email string `json:"emailAddress"`
+    DisplayName string `json:"displayName"`
+    Email       string `json:"emailAddress"`
+}
+
+type IssueSprint struct {
+    Name  string `json:"name"`
+    State string `json:"state"` // future, active or closed
+}
+
+type JiraUser struct {
+    AccountId   string `json:"accountId,omitempty"`
+    Name        string `json:"name,omitempty"`
+    DisplayName string `json:"displayName"`
+    Email       string `json:"emailAddress"`
 }
 
 type JiraError struct {
     ErrorMessages []string `json:"errorMessages"`
 }
 
+// ResponseError is returned for every response other than 200 OK.
+type ResponseError struct {
+    StatusCode int
+    Message    string
+}
+
+func (e *ResponseError) Error() string {
+    if e.Message == "" {
+        return fmt.Sprintf("unexpected response %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+    }
+
+    return e.Message
+}
+
 type Response struct {
     statusCode int
     body       []byte
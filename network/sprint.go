@@ -0,0 +1,104 @@
+package network
+
+import (
+    "encoding/json"
+    "regexp"
+    "strings"
+)
+
+var (
+    // Jira Data Center returns sprints as strings, e.g. "...Sprint@1f[id=1,rapidViewId=2,state=ACTIVE,name=Sprint 1,startDate=...]"
+    sprintNameRegx  = regexp.MustCompile(`[\[,]name=(.*?),\w+=`)
+    sprintStateRegx = regexp.MustCompile(`[\[,]state=(\w+)`)
+)
+
+// Sprint returns the active sprint of the issue, otherwise the latest one, nil without any.
+func (f IssueFields) Sprint() *IssueSprint {
+    if len(f.Sprints) == 0 {
+        return nil
+    }
+
+    for _, sprint := range f.Sprints {
+        if sprint.State == "active" {
+            return &sprint
+        }
+    }
+
+    return &f.Sprints[len(f.Sprints)-1]
+}
+
+// rawIssue keeps every field undecoded, the sprint field has no fixed name.
+type rawIssue struct {
+    Key    string                     `json:"key"`
+    Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// decodeSprints reads the sprint field of every issue in the bulk response.
+func decodeSprints(body []byte, sprintField string, jiraIssues []JiraIssue) error {
+    if sprintField == "" {
+        return nil
+    }
+
+    var raw struct {
+        Issues []rawIssue `json:"issues"`
+    }
+
+    if err := json.Unmarshal(body, &raw); err != nil {
+        return err
+    }
+
+    sprints := make(map[string][]IssueSprint)
+    for _, issue := range raw.Issues {
+        parsed, err := parseSprints(issue.Fields[sprintField])
+        if err != nil {
+            return err
+        }
+
+        sprints[issue.Key] = parsed
+    }
+
+    for i := range jiraIssues {
+        jiraIssues[i].Fields.Sprints = sprints[jiraIssues[i].Key]
+    }
+
+    return nil
+}
+
+// parseSprints reads a sprint field, Jira Cloud returns objects, Jira Data Center strings
+// and issues outside any sprint null.
+func parseSprints(field json.RawMessage) ([]IssueSprint, error) {
+    if len(field) == 0 || string(field) == "null" {
+        return nil, nil
+    }
+
+    var values []json.RawMessage
+    if err := json.Unmarshal(field, &values); err != nil {
+        return nil, err
+    }
+
+    sprints := make([]IssueSprint, 0, len(values))
+    for _, value := range values {
+        var text string
+        if err := json.Unmarshal(value, &text); err != nil {
+            var sprint IssueSprint
+            if err := json.Unmarshal(value, &sprint); err != nil {
+                return nil, err
+            }
+
+            sprints = append(sprints, sprint)
+            continue
+        }
+
+        sprint := IssueSprint{}
+        if match := sprintNameRegx.FindStringSubmatch(text); match != nil {
+            sprint.Name = match[1]
+        }
+        if match := sprintStateRegx.FindStringSubmatch(text); match != nil {
+            sprint.State = strings.ToLower(match[1])
+        }
+
+        sprints = append(sprints, sprint)
+    }
+
+    return sprints, nil
+}
@@ -0,0 +1,34 @@
+package network
+
+import (
+    "testing"
+)
+
+func TestDecodeSprints(t *testing.T) {
+    body := []byte(`{"issues":[
+        {"key":"ABC-1","fields":{"customfield_10020":[{"name":"Sprint 1","state":"closed"},{"name":"Sprint 2","state":"active"}]}},
+        {"key":"ABC-2","fields":{"customfield_10020":["com.atlassian.greenhopper.service.sprint.Sprint@1f[id=3,rapidViewId=2,state=FUTURE,name=Sprint 3, Q3,startDate=<null>]"]}},
+        {"key":"ABC-3","fields":{"customfield_10020":null}}
+    ]}`)
+
+    jiraIssues := []JiraIssue{{Key: "ABC-1"}, {Key: "ABC-2"}, {Key: "ABC-3"}}
+    if err := decodeSprints(body, "customfield_10020", jiraIssues); err != nil {
+        t.Fatal(err)
+    }
+
+    want := []*IssueSprint{{Name: "Sprint 2", State: "active"}, {Name: "Sprint 3, Q3", State: "future"}, nil}
+    for i, jiraIssue := range jiraIssues {
+        subject := jiraIssue.Fields.Sprint()
+        if (subject == nil) != (want[i] == nil) || (subject != nil && *subject != *want[i]) {
+            t.Errorf(`decodeSprints()[%d] = %+v, want match for %+v`, i, subject, want[i])
+        }
+    }
+}
+
+func TestDecodeSprintsUnexpected(t *testing.T) {
+    body := []byte(`{"issues":[{"key":"ABC-1","fields":{"customfield_10020":"Sprint 1"}}]}`)
+
+    if err := decodeSprints(body, "customfield_10020", []JiraIssue{{Key: "ABC-1"}}); err == nil {
+        t.Error(`decodeSprints() = nil, want error for a sprint field which is no array`)
+    }
+}
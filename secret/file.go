@@ -0,0 +1,54 @@
+package secret
+
+import (
+    "fmt"
+    "github.com/mitchellh/go-homedir"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+)
+
+// fileBackend keeps the secret in a file which only its owner may read.
+type fileBackend struct{}
+
+func (b *fileBackend) Get(path string, account Account) (string, error) {
+    path, err := homedir.Expand(path)
+    if err != nil {
+        return "", err
+    }
+
+    info, err := os.Stat(path)
+    if err != nil {
+        return "", err
+    }
+
+    if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+        return "", fmt.Errorf("%q must not be accessible by others, run chmod 600", path)
+    }
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return "", err
+    }
+
+    return strings.TrimSpace(string(data)), nil
+}
+
+func (b *fileBackend) Set(path string, account Account, secret string) error {
+    path, err := homedir.Expand(path)
+    if err != nil {
+        return err
+    }
+
+    if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+        return err
+    }
+
+    if err = os.WriteFile(path, []byte(secret+"\n"), 0o600); err != nil {
+        return err
+    }
+
+    // WriteFile keeps the mode of an existing file
+    return os.Chmod(path, 0o600)
+}
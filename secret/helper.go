@@ -0,0 +1,66 @@
+package secret
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "fmt"
+    "os/exec"
+    "strings"
+)
+
+// helperBackend runs an external command speaking the git credential helper protocol:
+// "<command> get" prints password=<secret>, "<command> store" reads it.
+type helperBackend struct{}
+
+func (b *helperBackend) Get(command string, account Account) (string, error) {
+    out, err := runHelper(command, "get", credentialInput(account, ""))
+    if err != nil {
+        return "", err
+    }
+
+    scanner := bufio.NewScanner(bytes.NewReader(out))
+    for scanner.Scan() {
+        key, value, _ := strings.Cut(scanner.Text(), "=")
+        if key == "password" {
+            return value, nil
+        }
+    }
+
+    return "", errors.New("helper did not return a password")
+}
+
+func (b *helperBackend) Set(command string, account Account, secret string) error {
+    _, err := runHelper(command, "store", credentialInput(account, secret))
+    return err
+}
+
+func runHelper(command, action, input string) ([]byte, error) {
+    cmd := exec.Command("sh", "-c", fmt.Sprintf("%s %s", command, action))
+    cmd.Stdin = strings.NewReader(input)
+
+    var stderr bytes.Buffer
+    cmd.Stderr = &stderr
+
+    out, err := cmd.Output()
+    if err != nil {
+        return nil, fmt.Errorf("%q %s: %w %s", command, action, err, strings.TrimSpace(stderr.String()))
+    }
+
+    return out, nil
+}
+
+func credentialInput(account Account, secret string) string {
+    var buffer strings.Builder
+
+    buffer.WriteString("protocol=https\n")
+    buffer.WriteString(fmt.Sprintf("host=%s\n", account.Host))
+    buffer.WriteString(fmt.Sprintf("username=%s\n", account.User))
+
+    if secret != "" {
+        buffer.WriteString(fmt.Sprintf("password=%s\n", secret))
+    }
+
+    buffer.WriteString("\n")
+    return buffer.String()
+}
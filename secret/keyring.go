@@ -0,0 +1,54 @@
+package secret
+
+import (
+    "errors"
+    "os/exec"
+    "runtime"
+    "strings"
+)
+
+// keyringBackend talks to the Secret Service through secret-tool on Linux
+// and to the login keychain through security on macOS.
+type keyringBackend struct{}
+
+func (b *keyringBackend) Get(service string, account Account) (string, error) {
+    var cmd *exec.Cmd
+
+    switch runtime.GOOS {
+    case "linux":
+        cmd = exec.Command("secret-tool", "lookup", "service", service, "account", account.User)
+    case "darwin":
+        cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", account.User, "-w")
+    default:
+        return "", errors.New("is not supported on " + runtime.GOOS)
+    }
+
+    out, err := cmd.Output()
+    if err != nil {
+        return "", err
+    }
+
+    return strings.TrimSpace(string(out)), nil
+}
+
+func (b *keyringBackend) Set(service string, account Account, secret string) error {
+    var cmd *exec.Cmd
+
+    switch runtime.GOOS {
+    case "linux":
+        cmd = exec.Command("secret-tool", "store", "--label", "twig "+account.Host, "service", service, "account", account.User)
+        cmd.Stdin = strings.NewReader(secret)
+    case "darwin":
+        // -w without a value prompts for the secret, so it is not exposed in the process list
+        cmd = exec.Command("security", "add-generic-password", "-U", "-s", service, "-a", account.User, "-w")
+        cmd.Stdin = strings.NewReader(secret + "\n" + secret + "\n") // entered and retyped
+    default:
+        return errors.New("is not supported on " + runtime.GOOS)
+    }
+
+    if out, err := cmd.CombinedOutput(); err != nil {
+        return errors.New(strings.TrimSpace(string(out)) + " " + err.Error())
+    }
+
+    return nil
+}
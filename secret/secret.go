@@ -0,0 +1,102 @@
+package secret
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "twig/log"
+)
+
+const (
+    SchemeKeyring = "keyring" // OS keyring, e.g. keyring:twig
+    SchemeHelper  = "helper"  // git credential style command, e.g. helper:pass-twig
+    SchemeFile    = "file"    // file readable by the owner only, e.g. file:~/.config/twig/token
+
+    DefaultKeyringService = "twig"
+)
+
+// Account identifies whose secret is stored, a backend decides which parts it needs.
+type Account struct {
+    Host string
+    User string
+}
+
+// Backend reads and writes secrets addressed by the part of a reference after the scheme.
+type Backend interface {
+    Get(name string, account Account) (string, error)
+    Set(name string, account Account, secret string) error
+}
+
+var backends = map[string]Backend{
+    SchemeKeyring: new(keyringBackend),
+    SchemeHelper:  new(helperBackend),
+    SchemeFile:    new(fileBackend),
+}
+
+// Parse splits a reference like keyring:twig into its scheme and name.
+// Values without a known scheme are plain secrets and ok is false.
+func Parse(value string) (scheme string, name string, ok bool) {
+    scheme, name, found := strings.Cut(value, ":")
+    if !found {
+        return "", "", false
+    }
+
+    if _, known := backends[scheme]; !known || strings.TrimSpace(name) == "" {
+        return "", "", false
+    }
+
+    return scheme, strings.TrimSpace(name), true
+}
+
+func IsReference(value string) bool {
+    _, _, ok := Parse(value)
+    return ok
+}
+
+// Resolve returns the secret the value refers to, plain values are returned as is.
+func Resolve(value string, account Account) (string, error) {
+    scheme, name, ok := Parse(value)
+    if !ok {
+        return value, nil
+    }
+
+    log.Debug().Println(fmt.Sprintf("Resolve secret from %q", scheme))
+
+    secret, err := backends[scheme].Get(name, account)
+    if err != nil {
+        return "", fmt.Errorf("secret: %s %w", scheme, err)
+    }
+
+    if secret == "" {
+        return "", fmt.Errorf("secret: %s %q is empty", scheme, name)
+    }
+
+    return secret, nil
+}
+
+// Store saves the secret into the backend the reference points to.
+func Store(reference string, account Account, secret string) error {
+    scheme, name, ok := Parse(reference)
+    if !ok {
+        return errors.New("secret: unsupported reference, use keyring:<service>, helper:<command> or file:<path>")
+    }
+
+    if err := backends[scheme].Set(name, account, secret); err != nil {
+        return fmt.Errorf("secret: %s %w", scheme, err)
+    }
+
+    return nil
+}
+
+// Mask hides plain secrets, references are safe to show.
+func Mask(value string) string {
+    if value == "" || IsReference(value) {
+        return value
+    }
+
+    if len(value) < 12 {
+        return "********"
+    }
+
+    return "********" + value[len(value)-4:]
+}
@@ -0,0 +1,110 @@
+package secret
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "twig/log"
+)
+
+var account = Account{Host: "example.atlassian.net", User: "example.user@example.com"}
+
+func init() {
+    log.CreateNoOpTestRecorders()
+}
+
+func TestResolvePlainValue(t *testing.T) {
+    in := "pat_n7rt64i"
+
+    subject, err := Resolve(in, account)
+
+    if err != nil || subject != in {
+        t.Errorf(`Resolve(in) = %q, %v, want match for %q`, subject, err, in)
+    }
+}
+
+func TestResolveUnknownSchemeIsPlain(t *testing.T) {
+    in := "vault:twig"
+
+    subject, err := Resolve(in, account)
+
+    if err != nil || subject != in {
+        t.Errorf(`Resolve(in) = %q, %v, want match for %q`, subject, err, in)
+    }
+}
+
+func TestStoreAndResolveFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "token")
+    in := "file:" + path
+
+    if err := Store(in, account, "pat_n7rt64i"); err != nil {
+        t.Fatal(err)
+    }
+
+    want := "pat_n7rt64i"
+    subject, err := Resolve(in, account)
+
+    if err != nil || subject != want {
+        t.Errorf(`Resolve(in) = %q, %v, want match for %q`, subject, err, want)
+    }
+}
+
+func TestResolveFileReadableByOthers(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "token")
+    if err := os.WriteFile(path, []byte("pat_n7rt64i"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    _, err := Resolve("file:"+path, account)
+
+    if err == nil {
+        t.Errorf(`Resolve(in) = nil, want error`)
+    }
+}
+
+func TestResolveHelper(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "helper")
+    script := "#!/bin/sh\ncat > /dev/null\necho username=example\necho password=pat_n7rt64i\n"
+    if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+        t.Fatal(err)
+    }
+
+    want := "pat_n7rt64i"
+    subject, err := Resolve("helper:"+path, account)
+
+    if err != nil || subject != want {
+        t.Errorf(`Resolve(in) = %q, %v, want match for %q`, subject, err, want)
+    }
+}
+
+func TestMaskPlainValue(t *testing.T) {
+    in := "dXNlckBleGFtcGx1234"
+
+    want := "********1234"
+    subject := Mask(in)
+
+    if subject != want {
+        t.Errorf(`Mask(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestMaskShortValue(t *testing.T) {
+    in := "short"
+
+    want := "********"
+    subject := Mask(in)
+
+    if subject != want {
+        t.Errorf(`Mask(in) = %q, want match for %q`, subject, want)
+    }
+}
+
+func TestMaskReference(t *testing.T) {
+    in := "keyring:twig"
+
+    subject := Mask(in)
+
+    if subject != in {
+        t.Errorf(`Mask(in) = %q, want match for %q`, subject, in)
+    }
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+    "regexp"
+    "strings"
+)
+
+// MatchGlob reports whether the name matches the pattern.
+// A '*' matches any sequence of characters including '/', a '?' matches exactly one character.
+func MatchGlob(pattern, name string) bool {
+    var buffer strings.Builder
+    buffer.WriteString("^")
+
+    for _, r := range pattern {
+        switch r {
+        case '*':
+            buffer.WriteString(".*")
+        case '?':
+            buffer.WriteString(".")
+        default:
+            buffer.WriteString(regexp.QuoteMeta(string(r)))
+        }
+    }
+
+    buffer.WriteString("$")
+
+    return regexp.MustCompile(buffer.String()).MatchString(name)
+}